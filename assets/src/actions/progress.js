@@ -0,0 +1,29 @@
+export const REQUEST_PROGRESS = 'REQUEST_PROGRESS'
+export const RECEIVE_PROGRESS = 'RECEIVE_PROGRESS'
+export const CLEAR_PROGRESS = 'CLEAR_PROGRESS'
+
+
+export const clearProgress = () => ({
+  type: CLEAR_PROGRESS
+})
+
+export const requestProgress = (task_id, network, chain_id) => ({
+  type: REQUEST_PROGRESS,
+  task_id: task_id,
+  chain_id: chain_id,
+  network: network,
+})
+
+export const receiveProgress = (json) => ({
+  type: RECEIVE_PROGRESS,
+  progress: json,
+  receivedAt: Date.now()
+})
+
+export const fetchProgress = (task_id, network, chain_id) => dispatch => {
+  dispatch(requestProgress(task_id, network, chain_id))
+  const params = new URLSearchParams({ task_id, network, chain_id })
+  return fetch(`/scheduler/runner/syncrange/progress?` + params.toString())
+    .then(response => response.json())
+    .then(json => dispatch(receiveProgress(json)))
+}
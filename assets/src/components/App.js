@@ -1,6 +1,7 @@
 import React from 'react'
 import Tasks from '../containers/Tasks'
 import LastData from '../containers/LastData'
+import Progress from '../containers/Progress'
 import NewTask from '../containers/NewTask'
 
 import Container from 'react-bootstrap/Container'
@@ -19,6 +20,9 @@ const App = () => (
         <Row>
           <Tasks />
         </Row>
+        <Row>
+          <Progress />
+        </Row>
         <Row>
           <LastData />
         </Row>
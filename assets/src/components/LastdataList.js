@@ -11,6 +11,7 @@ const LastdataList = ({lastdatas}) => (
         <th>height</th>
         <th>hash</th>
         <th>error</th>
+        <th>error class</th>
         <th>nonce</th>
     </tr>
     </thead>
@@ -21,6 +22,7 @@ const LastdataList = ({lastdatas}) => (
       <td>{ld.height}</td>
       <td>{ld.hash}</td>
       <td>{ld.error}</td>
+      <td>{ld.error_class}</td>
       <td>{ld.none}</td>
       </tr>
     )}
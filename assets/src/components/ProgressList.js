@@ -0,0 +1,54 @@
+import React from 'react'
+import PropTypes from 'prop-types'
+import { Table } from "react-bootstrap";
+import ProgressBar from 'react-bootstrap/ProgressBar'
+
+
+// zero time is sent when the value is not known
+function formatTime(t) {
+  return (!t || t.startsWith("0001-01-01")) ? "" : t
+}
+
+const ProgressList = ({progress}) => (
+  <Table striped bordered condensed hover>
+    <thead>
+    <tr>
+        <th>range</th>
+        <th>chunks</th>
+        <th>progress</th>
+        <th>blocks / min</th>
+        <th>eta</th>
+        <th>started</th>
+        <th>last run</th>
+        <th>runs</th>
+        <th>errors</th>
+        <th>retries</th>
+        <th>last error</th>
+    </tr>
+    </thead>
+    {progress.map((p, i) =>
+      <tr key={i}>
+      <td>{p.height_from} - {p.height_to}</td>
+      <td>{p.chunks > 1 ? p.chunks : ""}</td>
+      <td>
+        <ProgressBar now={p.percent} label={p.percent.toFixed(2) + "%"} variant={p.finished ? "success" : undefined} />
+        <small>{p.synced} / {p.height_to - p.height_from}</small>
+      </td>
+      <td>{p.blocks_per_minute.toFixed(1)} <small>({p.window})</small></td>
+      <td>{p.finished ? "finished" : formatTime(p.eta)}</td>
+      <td>{formatTime(p.started_at)}</td>
+      <td>{formatTime(p.last_run)}</td>
+      <td>{p.runs}</td>
+      <td>{p.errors}</td>
+      <td>{p.retry_count}</td>
+      <td>{p.last_error}</td>
+      </tr>
+    )}
+  </Table>
+)
+
+ProgressList.propTypes = {
+    progress: PropTypes.array.isRequired
+}
+
+export default ProgressList
@@ -4,6 +4,11 @@ import { Table } from "react-bootstrap";
 import Button from 'react-bootstrap/Button'
 
 
+// zero time is sent for the runs that did not happen yet
+function formatTime(t) {
+  return (!t || t.startsWith("0001-01-01")) ? "" : t
+}
+
 class TaskList extends React.Component {
 
   clickLoadTaskInformation(task_id, network, chain_id, kind, e) {
@@ -26,9 +31,13 @@ class TaskList extends React.Component {
         <th>chain_id</th>
         <th>kind</th>
         <th>duration</th>
+        <th>cron</th>
         <th>status</th>
+        <th>last run</th>
+        <th>next run</th>
         <th>enabled</th>
         <th>config</th>
+        <th>labels</th>
         <th></th>
     </tr>
     </thead>
@@ -39,13 +48,21 @@ class TaskList extends React.Component {
         <td>{task.chain_id}</td>
         <td>{task.kind}</td>
         <td>{task.duration}</td>
-        <td>{task.status} </td>
+        <td>{task.cron}</td>
+        <td>{task.status} {task.status === "failed" &&
+          <div><small>{task.failed_at}: {task.last_error}</small></div>
+        }</td>
+        <td>{formatTime(task.last_run)}</td>
+        <td>{formatTime(task.next_run)} {task.backoff_iteration > 0 &&
+          <div><small>backoff {task.backoff_iteration}</small></div>
+        }</td>
         <td>
           {task.enabled
             ? <Button onClick={(e) => this.clickDisableTask(task.id, e)} >enabled</Button>
             : <Button onClick={(e) => this.clickEnableTask(task.id, e)} >disabled</Button>
           } </td>
         <td>{JSON.stringify(task.config)}</td>
+        <td>{Object.keys(task.labels || {}).map((k) => k + "=" + task.labels[k]).join(", ")}</td>
         <td ><Button onClick={(e) => this.clickLoadTaskInformation(task.task_id, task.network, task.chain_id, task.kind, e)}  >See </Button> </td>
       </tr>
     )}
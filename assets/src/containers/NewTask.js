@@ -36,6 +36,14 @@ class NewTask extends Component {
       network: this.networkVal.value,
       chain_id: this.chainIDVal.value,
       interval: this.intervalVal.value,
+      cron: this.cronVal.value,
+    }
+
+    if (this.startAtVal.value != "") {
+      addTaskPayload['start_at'] = this.startAtVal.value
+    }
+    if (this.endAtVal.value != "") {
+      addTaskPayload['end_at'] = this.endAtVal.value
     }
 
     if (this.kindVal.value == "syncrange") {
@@ -93,6 +101,30 @@ class NewTask extends Component {
               Interval of the least time between runs. Usual go parsing is applied, so 10s, 2m, 4w
             </Form.Text>
           </Form.Group>
+
+          <Form.Group controlId="newTaskCron">
+            <Form.Label>Cron</Form.Label>
+            <Form.Control type="text" placeholder="Enter cron expression"  ref={node => (this.cronVal = node)}  />
+            <Form.Text className="text-muted">
+              Optional cron expression (minute hour day-of-month month day-of-week) evaluated in UTC, eg. `*/5 0-6 * * *`. Takes precedence over interval
+            </Form.Text>
+          </Form.Group>
+
+          <Form.Group controlId="newTaskStartAt">
+            <Form.Label>Start At</Form.Label>
+            <Form.Control type="text" placeholder="Enter start time"  ref={node => (this.startAtVal = node)}  />
+            <Form.Text className="text-muted">
+              Optional RFC3339 time, eg. `2021-06-01T00:00:00Z`. Task does not run before it
+            </Form.Text>
+          </Form.Group>
+
+          <Form.Group controlId="newTaskEndAt">
+            <Form.Label>End At</Form.Label>
+            <Form.Control type="text" placeholder="Enter end time"  ref={node => (this.endAtVal = node)}  />
+            <Form.Text className="text-muted">
+              Optional RFC3339 time. Task is finished after it
+            </Form.Text>
+          </Form.Group>
           {this.props.addTaskTypePicked == "syncrange"
             ? <Container>
               <h3>Sync Range params:</h3>
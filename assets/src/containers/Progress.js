@@ -0,0 +1,61 @@
+import React, { Component } from 'react'
+import PropTypes from 'prop-types'
+import { connect } from 'react-redux'
+
+import Button from 'react-bootstrap/Button'
+import Container from 'react-bootstrap/Container'
+import Row from 'react-bootstrap/Row'
+import Col from 'react-bootstrap/Col'
+
+
+import ProgressList from '../components/ProgressList'
+import { fetchProgress } from '../actions/progress'
+
+
+class Progress extends Component {
+    static propTypes = {
+      list: PropTypes.array.isRequired,
+
+      task_id: PropTypes.string.isRequired,
+      network: PropTypes.string.isRequired,
+      chain_id: PropTypes.string.isRequired,
+
+      dispatch: PropTypes.func.isRequired,
+    }
+
+
+  handleRefreshClick() {
+    const { dispatch } = this.props
+    dispatch(fetchProgress(this.props.task_id, this.props.network, this.props.chain_id))
+  }
+
+
+  render() {
+    const { list, network, chain_id, task_id } = this.props
+    if (list === null || list.length === 0) {
+      return ""
+    }
+    return (
+      <Container>
+        <Row >
+          <Col><h2>Progress - {task_id} {network} {chain_id}</h2></Col>
+          <Col xs={1}><Button variant="outline-dark" onClick={(e) => this.handleRefreshClick()}>Refresh</Button></Col>
+        </Row>
+        <Row>
+          < ProgressList progress={list} />
+        </Row>
+      </Container>
+    )
+  }
+}
+
+const mapStateToProps = (state) => {
+  return {
+    list: state.progress.list,
+    task_id: state.progress.task_id,
+    network: state.progress.network,
+    chain_id: state.progress.chain_id,
+  }
+}
+
+export default connect(mapStateToProps)(Progress)
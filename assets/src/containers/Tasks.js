@@ -11,6 +11,7 @@ import Col from 'react-bootstrap/Col'
 
 import { fetchTasksIfNeeded, invalidateTasks, enableTask, disableTask  } from '../actions'
 import { fetchLastData, invalidateLastdata  } from '../actions/lastdata'
+import { fetchProgress, clearProgress  } from '../actions/progress'
 
 
 class Tasks extends Component {
@@ -43,6 +44,11 @@ class Tasks extends Component {
     const { dispatch } = this.props
     dispatch(invalidateLastdata())
     dispatch(fetchLastData(task_id,  network,  chain_id, kind, 100, 0))
+    if (kind === "syncrange") {
+      dispatch(fetchProgress(task_id,  network,  chain_id))
+    } else {
+      dispatch(clearProgress())
+    }
   }
 
   enableTask(task_id,  network,  chain_id, kind) {
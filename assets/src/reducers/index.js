@@ -2,9 +2,10 @@ import { combineReducers } from 'redux'
 
 import tasks from './tasks'
 import lastdata from './lastdata'
+import progress from './progress'
 
 const uiApp = combineReducers({
-  tasks, lastdata
+  tasks, lastdata, progress
 })
 
 export default uiApp
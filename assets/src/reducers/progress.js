@@ -0,0 +1,39 @@
+import {
+  RECEIVE_PROGRESS, REQUEST_PROGRESS, CLEAR_PROGRESS
+} from '../actions/progress'
+
+
+const progress = (state = {
+    isFetching: false,
+    task_id: "",
+    chain_id: "",
+    network: "",
+    list: []
+  }, action) => {
+    switch (action.type) {
+      case CLEAR_PROGRESS:
+        return {
+          ...state,
+          list: []
+        }
+      case REQUEST_PROGRESS:
+        return {
+          ...state,
+          task_id: action.task_id,
+          chain_id: action.chain_id,
+          network: action.network,
+          isFetching: true
+        }
+      case RECEIVE_PROGRESS:
+        return {
+          ...state,
+          isFetching: false,
+          list: Array.isArray(action.progress) ? action.progress : [],
+          lastUpdated: action.receivedAt
+        }
+      default:
+        return state
+    }
+  }
+
+  export default progress
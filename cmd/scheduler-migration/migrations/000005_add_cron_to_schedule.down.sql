@@ -0,0 +1 @@
+ALTER TABLE schedule DROP COLUMN cron;
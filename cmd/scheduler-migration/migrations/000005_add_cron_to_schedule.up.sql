@@ -0,0 +1 @@
+ALTER TABLE schedule ADD COLUMN cron TEXT NOT NULL DEFAULT '';
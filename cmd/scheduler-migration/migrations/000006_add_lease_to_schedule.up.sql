@@ -0,0 +1 @@
+ALTER TABLE schedule ADD COLUMN lease_expires TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1 @@
+ALTER TABLE schedule ADD COLUMN revision BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE schedule ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+ALTER TABLE schedule ADD COLUMN retry_policy JSONB;
@@ -0,0 +1,2 @@
+ALTER TABLE schedule DROP COLUMN failed_at;
+ALTER TABLE schedule DROP COLUMN last_error;
@@ -0,0 +1,2 @@
+ALTER TABLE schedule ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE schedule ADD COLUMN failed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+ALTER TABLE schedule DROP COLUMN timeout;
+ALTER TABLE schedule_syncrange DROP COLUMN error_class;
+ALTER TABLE schedule_latest DROP COLUMN error_class;
@@ -0,0 +1,3 @@
+ALTER TABLE schedule_latest ADD COLUMN error_class TEXT NOT NULL DEFAULT '';
+ALTER TABLE schedule_syncrange ADD COLUMN error_class TEXT NOT NULL DEFAULT '';
+ALTER TABLE schedule ADD COLUMN timeout BIGINT NOT NULL DEFAULT 0;
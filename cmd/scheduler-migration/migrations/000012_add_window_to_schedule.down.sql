@@ -0,0 +1,2 @@
+ALTER TABLE schedule DROP COLUMN end_at;
+ALTER TABLE schedule DROP COLUMN start_at;
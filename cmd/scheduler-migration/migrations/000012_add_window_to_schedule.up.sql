@@ -0,0 +1,2 @@
+ALTER TABLE schedule ADD COLUMN start_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE schedule ADD COLUMN end_at TIMESTAMP WITH TIME ZONE;
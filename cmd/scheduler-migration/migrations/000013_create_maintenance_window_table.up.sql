@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS maintenance_window
+(
+    id          uuid DEFAULT uuid_generate_v4(),
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    description TEXT NOT NULL DEFAULT '',
+
+    network     VARCHAR(100) NOT NULL DEFAULT '',
+    chain_id    VARCHAR(100) NOT NULL DEFAULT '',
+    selector    TEXT NOT NULL DEFAULT '',
+
+    start_at    TIMESTAMP WITH TIME ZONE,
+    end_at      TIMESTAMP WITH TIME ZONE,
+
+    cron        TEXT NOT NULL DEFAULT '',
+    duration    BIGINT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE schedule DROP COLUMN jitter;
+ALTER TABLE schedule DROP COLUMN start_offset;
@@ -0,0 +1,2 @@
+ALTER TABLE schedule ADD COLUMN start_offset BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE schedule ADD COLUMN jitter BIGINT NOT NULL DEFAULT 0;
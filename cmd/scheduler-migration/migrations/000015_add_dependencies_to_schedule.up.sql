@@ -0,0 +1 @@
+ALTER TABLE schedule ADD COLUMN dependencies JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,4 @@
+ALTER TABLE schedule DROP COLUMN backoff_iteration;
+ALTER TABLE schedule DROP COLUMN next_run;
+ALTER TABLE schedule DROP COLUMN last_run;
+ALTER TABLE schedule DROP COLUMN missed_runs;
@@ -0,0 +1,4 @@
+ALTER TABLE schedule ADD COLUMN missed_runs VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE schedule ADD COLUMN last_run TIMESTAMP WITH TIME ZONE;
+ALTER TABLE schedule ADD COLUMN next_run TIMESTAMP WITH TIME ZONE;
+ALTER TABLE schedule ADD COLUMN backoff_iteration BIGINT NOT NULL DEFAULT 0;
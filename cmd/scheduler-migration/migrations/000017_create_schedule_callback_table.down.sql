@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS sch_clb_nvc;
+DROP TABLE IF EXISTS schedule_callback;
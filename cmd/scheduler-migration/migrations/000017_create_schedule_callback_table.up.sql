@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS schedule_callback
+(
+    id          uuid DEFAULT uuid_generate_v4(),
+    time        TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    network     VARCHAR(100)  NOT NULL,
+    chain_id    VARCHAR(100)  NOT NULL,
+    version     VARCHAR(50)  NOT NULL,
+    kind        VARCHAR(100),
+    task_id     VARCHAR(100)  NOT NULL,
+
+    height      BIGINT NOT NULL DEFAULT 0,
+    data        JSONB NOT NULL DEFAULT '{}',
+
+    error       TEXT,
+    error_class TEXT NOT NULL DEFAULT '',
+
+    PRIMARY KEY (id)
+);
+
+
+CREATE INDEX IF NOT EXISTS sch_clb_nvc on schedule_callback(network, chain_id, version, kind, task_id, time);
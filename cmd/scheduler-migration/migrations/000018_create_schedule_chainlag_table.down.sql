@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS sch_chlg_nvc;
+DROP TABLE IF EXISTS schedule_chainlag;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS schedule_gap;
+DROP INDEX IF EXISTS sch_gps_nvc;
+DROP TABLE IF EXISTS schedule_gap_scan;
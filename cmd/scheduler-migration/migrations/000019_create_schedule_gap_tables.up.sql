@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS schedule_gap_scan
+(
+    id            uuid DEFAULT uuid_generate_v4(),
+    time          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    network       VARCHAR(100)  NOT NULL,
+    chain_id      VARCHAR(100)  NOT NULL,
+    version       VARCHAR(50)  NOT NULL,
+    kind          VARCHAR(100),
+    task_id       VARCHAR(100)  NOT NULL,
+
+    scanned_until TIMESTAMP WITH TIME ZONE,
+    last_height   BIGINT NOT NULL DEFAULT 0,
+    gaps          BIGINT NOT NULL DEFAULT 0,
+
+    error         TEXT,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS sch_gps_nvc on schedule_gap_scan(network, chain_id, version, kind, task_id, time);
+
+CREATE TABLE IF NOT EXISTS schedule_gap
+(
+    id               uuid DEFAULT uuid_generate_v4(),
+    detected_at      TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    network          VARCHAR(100)  NOT NULL,
+    chain_id         VARCHAR(100)  NOT NULL,
+    version          VARCHAR(50)  NOT NULL,
+    task_id          VARCHAR(100)  NOT NULL,
+
+    kind             VARCHAR(20) NOT NULL,
+    height_from      BIGINT NOT NULL,
+    height_to        BIGINT NOT NULL,
+
+    backfill_task_id VARCHAR(100) NOT NULL DEFAULT '',
+
+    PRIMARY KEY (id),
+    UNIQUE (network, chain_id, version, task_id, kind, height_from, height_to)
+);
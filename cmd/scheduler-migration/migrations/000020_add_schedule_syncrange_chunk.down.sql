@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS sch_srng_nvcc;
+ALTER TABLE schedule_syncrange DROP COLUMN chunk;
@@ -0,0 +1,3 @@
+ALTER TABLE schedule_syncrange ADD COLUMN chunk INT NOT NULL DEFAULT -1;
+
+CREATE INDEX IF NOT EXISTS sch_srng_nvcc on schedule_syncrange(network, chain_id, version, kind, task_id, chunk, time);
@@ -0,0 +1 @@
+ALTER TABLE schedule_syncrange DROP COLUMN started_at;
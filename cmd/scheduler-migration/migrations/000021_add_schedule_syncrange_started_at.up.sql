@@ -0,0 +1 @@
+ALTER TABLE schedule_syncrange ADD COLUMN started_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1 @@
+ALTER TABLE maintenance_window DROP COLUMN all_schedules;
@@ -0,0 +1,2 @@
+ALTER TABLE maintenance_window ADD COLUMN all_schedules BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE maintenance_window SET all_schedules = TRUE WHERE network = '' AND chain_id = '' AND selector = '';
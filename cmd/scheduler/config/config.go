@@ -2,6 +2,8 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"time"
 
@@ -35,10 +37,83 @@ type Config struct {
 	DestinationsConfig string `json:"destinations_config" envconfig:"DESTINATIONS_CONFIG"`
 	DestinationsValue  string `json:"destinations_value" envconfig:"DESTINATIONS_VALUE"`
 
+	// PluginsConfig is the path of file listing executables serving out-of-tree task kinds
+	PluginsConfig string `json:"plugins_config" envconfig:"PLUGINS_CONFIG"`
+
+	// ConfigReloadInterval is the interval of checking config directories for changes, 0 disables it. SIGHUP always reloads configs.
+	ConfigReloadInterval time.Duration `json:"config_reload_interval" envconfig:"CONFIG_RELOAD_INTERVAL" default:"0"`
+
 	AuthUser     string `json:"auth_user" envconfig:"AUTH_USER"`
 	AuthPassword string `json:"auth_password" envconfig:"AUTH_PASSWORD"`
 
 	HealthCheckInterval time.Duration `json:"health_check_interval" envconfig:"HEALTH_CHECK_INTERVAL" default:"10s"`
+
+	// Schedule ownership between scheduler instances
+	LeaseTTL          time.Duration `json:"lease_ttl" envconfig:"LEASE_TTL" default:"30s"`
+	HeartbeatInterval time.Duration `json:"heartbeat_interval" envconfig:"HEARTBEAT_INTERVAL" default:"10s"`
+
+	// Maximum numbers of concurrent runs, 0 means no limit
+	ConcurrencyGlobal     uint64 `json:"concurrency_global" envconfig:"CONCURRENCY_GLOBAL" default:"0"`
+	ConcurrencyPerNetwork uint64 `json:"concurrency_per_network" envconfig:"CONCURRENCY_PER_NETWORK" default:"0"`
+	ConcurrencyPerAddress uint64 `json:"concurrency_per_address" envconfig:"CONCURRENCY_PER_ADDRESS" default:"0"`
+
+	// CatchUpSpacing is the minimum time between runs of lastdata tasks which height keeps advancing, 0 disables catching up
+	CatchUpSpacing time.Duration `json:"catch_up_spacing" envconfig:"CATCH_UP_SPACING" default:"0"`
+
+	// ShutdownGracePeriod is the time given to in-flight runs to finish on shutdown
+	ShutdownGracePeriod time.Duration `json:"shutdown_grace_period" envconfig:"SHUTDOWN_GRACE_PERIOD" default:"30s"`
+}
+
+// Defaults of settings which zero value is not usable. Envconfig applies them from tags, config read from file gets them from SetDefaults.
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultLeaseTTL            = 30 * time.Second
+	defaultHeartbeatInterval   = 10 * time.Second
+	defaultShutdownGracePeriod = 30 * time.Second
+)
+
+// SetDefaults sets the settings that were not given
+func (c *Config) SetDefaults() {
+	if c.HealthCheckInterval == 0 {
+		c.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if c.LeaseTTL == 0 {
+		c.LeaseTTL = defaultLeaseTTL
+	}
+	if c.HeartbeatInterval == 0 {
+		c.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	if c.ShutdownGracePeriod == 0 {
+		c.ShutdownGracePeriod = defaultShutdownGracePeriod
+	}
+}
+
+// Validate checks the durations, no matter where the config was read from
+func (c Config) Validate() error {
+	positive := map[string]time.Duration{
+		"health_check_interval": c.HealthCheckInterval,
+		"lease_ttl":             c.LeaseTTL,
+		"heartbeat_interval":    c.HeartbeatInterval,
+		"shutdown_grace_period": c.ShutdownGracePeriod,
+	}
+	for name, d := range positive {
+		if d <= 0 {
+			return fmt.Errorf("%s has to be positive", name)
+		}
+	}
+
+	if c.ConfigReloadInterval < 0 {
+		return errors.New("config_reload_interval cannot be negative")
+	}
+	if c.CatchUpSpacing < 0 {
+		return errors.New("catch_up_spacing cannot be negative")
+	}
+
+	// lease has to be renewed before it expires
+	if c.HeartbeatInterval >= c.LeaseTTL {
+		return errors.New("heartbeat_interval has to be shorter than lease_ttl")
+	}
+	return nil
 }
 
 // FromFile reads the config from a file
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/figment-networks/indexer-scheduler/cmd/scheduler/config"
+	"github.com/figment-networks/indexer-scheduler/structures"
+)
+
+// readDestinations reads destinations from env var value or, if not set, from the config directory
+func readDestinations(cfg config.Config) (trgts []structures.TargetConfig, err error) {
+	if cfg.DestinationsValue != "" {
+		dec := json.NewDecoder(strings.NewReader(cfg.DestinationsValue))
+		if err := dec.Decode(&trgts); err != nil {
+			return nil, fmt.Errorf("error reading config from env (decode): %w", err)
+		}
+		return trgts, nil
+	}
+
+	if cfg.DestinationsConfig == "" {
+		return nil, nil
+	}
+
+	files, err := ioutil.ReadDir(cfg.DestinationsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error reading destinations config dir: %w", err)
+	}
+
+	for _, fileInfo := range files {
+		if fileInfo.IsDir() {
+			continue
+		}
+
+		file, err := os.Open(path.Join(cfg.DestinationsConfig, fileInfo.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", path.Join(cfg.DestinationsConfig, fileInfo.Name()), err)
+		}
+
+		fileTrgts := []structures.TargetConfig{}
+		dec := json.NewDecoder(file)
+		err = dec.Decode(&fileTrgts)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file (decode) %s: %w", path.Join(cfg.DestinationsConfig, fileInfo.Name()), err)
+		}
+
+		trgts = append(trgts, fileTrgts...)
+	}
+
+	return trgts, nil
+}
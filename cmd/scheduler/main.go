@@ -4,16 +4,12 @@ import (
 	"context"
 	"crypto/tls"
 	"database/sql"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
-	"path"
-	"strings"
 	"syscall"
 	"time"
 
@@ -28,11 +24,27 @@ import (
 	"github.com/figment-networks/indexer-scheduler/core"
 	"github.com/figment-networks/indexer-scheduler/destination"
 	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/maintenance"
+	maintenancePersistence "github.com/figment-networks/indexer-scheduler/maintenance/persistence"
+	maintenanceDatabase "github.com/figment-networks/indexer-scheduler/maintenance/persistence/postgresstore"
 	"github.com/figment-networks/indexer-scheduler/persistence"
 	"github.com/figment-networks/indexer-scheduler/persistence/postgresstore"
 	"github.com/figment-networks/indexer-scheduler/process"
 	"github.com/figment-networks/indexer-scheduler/ui"
 
+	"github.com/figment-networks/indexer-scheduler/runner/callback"
+	runnerCallbackPersistence "github.com/figment-networks/indexer-scheduler/runner/callback/persistence"
+	runnerCallbackDatabase "github.com/figment-networks/indexer-scheduler/runner/callback/persistence/postgresstore"
+	runnerCallbackHTTP "github.com/figment-networks/indexer-scheduler/runner/callback/transport/http"
+	runnerCallbackWS "github.com/figment-networks/indexer-scheduler/runner/callback/transport/ws"
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag"
+	runnerChainlagPersistence "github.com/figment-networks/indexer-scheduler/runner/chainlag/persistence"
+	runnerChainlagDatabase "github.com/figment-networks/indexer-scheduler/runner/chainlag/persistence/postgresstore"
+	runnerChainlagHTTP "github.com/figment-networks/indexer-scheduler/runner/chainlag/transport/http"
+	runnerChainlagWS "github.com/figment-networks/indexer-scheduler/runner/chainlag/transport/ws"
+	"github.com/figment-networks/indexer-scheduler/runner/gapdetect"
+	runnerGapdetectPersistence "github.com/figment-networks/indexer-scheduler/runner/gapdetect/persistence"
+	runnerGapdetectDatabase "github.com/figment-networks/indexer-scheduler/runner/gapdetect/persistence/postgresstore"
 	"github.com/figment-networks/indexer-scheduler/runner/lastdata"
 	runnerPersistence "github.com/figment-networks/indexer-scheduler/runner/lastdata/persistence"
 	runnerDatabase "github.com/figment-networks/indexer-scheduler/runner/lastdata/persistence/postgresstore"
@@ -45,14 +57,13 @@ import (
 	runnerSyncrangeHTTP "github.com/figment-networks/indexer-scheduler/runner/syncrange/transport/http"
 	runnerSyncrangeWS "github.com/figment-networks/indexer-scheduler/runner/syncrange/transport/ws"
 
-	"github.com/figment-networks/indexer-scheduler/structures"
-
 	_ "github.com/lib/pq"
 )
 
 type flags struct {
 	configPath  string
 	showVersion bool
+	plan        bool
 }
 
 var configFlags = flags{}
@@ -60,6 +71,7 @@ var configFlags = flags{}
 func init() {
 	flag.BoolVar(&configFlags.showVersion, "v", false, "Show application version")
 	flag.StringVar(&configFlags.configPath, "config", "", "Path to config")
+	flag.BoolVar(&configFlags.plan, "plan", false, "Print changes needed to reconcile schedules with SCHEDULES_CONFIG and exit")
 	flag.Parse()
 }
 
@@ -139,6 +151,13 @@ func main() {
 
 	d := postgresstore.NewDriver(db)
 	sch := process.NewScheduler(logger, d)
+	limiter := process.NewLimiter(process.LimiterConfig{
+		Global:     cfg.ConcurrencyGlobal,
+		PerNVC:     cfg.ConcurrencyPerNetwork,
+		PerAddress: cfg.ConcurrencyPerAddress,
+	})
+	sch.SetLimiter(limiter)
+	sch.SetCatchUp(cfg.CatchUpSpacing)
 
 	cStore := &persistence.CoreStorage{Driver: d}
 
@@ -147,8 +166,24 @@ func main() {
 		Password: cfg.AuthPassword,
 	}
 
-	c := core.NewCore(cStore, sch, creds, logger)
+	c := core.NewCore(cStore, sch, creds, cfg.LeaseTTL, logger)
+
+	if configFlags.plan {
+		if err := printSchedulesPlan(ctx, c, cfg.SchedulesConfig); err != nil {
+			logger.Fatal("Error planning schedules", zap.Error(err))
+		}
+		return
+	}
+
 	c.RegisterHandles(mux)
+
+	mm := maintenance.NewManager(logger, maintenancePersistence.NewMaintenanceStorage(maintenanceDatabase.NewDriver(db)), creds)
+	if err := mm.Load(ctx); err != nil {
+		logger.Error("[Scheduler] Error loading maintenance windows", zap.Error(err))
+	}
+	mm.RegisterHandles(mux)
+	sch.AddGate(mm)
+
 	scheme := destination.NewScheme(logger, creds)
 	scheme.RegisterHandles(mux)
 
@@ -159,122 +194,18 @@ func main() {
 		logger.Error("[Scheduler] Error during initial load of scheduler", zap.Error(err))
 		logger.Sync()
 	}
-	if cfg.SchedulesConfig != "" {
-		logger.Info("[Scheduler] Loading schedule initial config")
-
-		files, err := ioutil.ReadDir(cfg.SchedulesConfig)
-		if err != nil {
-			logger.Fatal("Error reading scheduling config dir", zap.Error(err))
-			return
-		}
-
-		rcs := []structures.RunConfig{}
-		for _, fileInfo := range files {
-			if fileInfo.IsDir() {
-				continue
-			}
-
-			file, err := os.Open(path.Join(cfg.SchedulesConfig, fileInfo.Name()))
-			if err != nil {
-				logger.Fatal("Error reading config file", zap.Error(err), zap.String("filepath", path.Join(cfg.SchedulesConfig, fileInfo.Name())))
-				return
-			}
-
-			rcp := []structures.RunConfigParams{}
-			dec := json.NewDecoder(file)
-			err = dec.Decode(&rcp)
-			file.Close()
-			if err != nil {
-				logger.Fatal("Error reading config file (decode)", zap.Error(err), zap.String("filepath", path.Join(cfg.SchedulesConfig, fileInfo.Name())))
-				return
-			}
-
-			for _, rConf := range rcp {
-				duration, err := time.ParseDuration(rConf.Interval)
-				if err != nil {
-					logger.Fatal("Error parsing duration ", zap.Error(err), zap.String("filepath", path.Join(cfg.SchedulesConfig, fileInfo.Name())))
-					return
-				}
-				rcs = append(rcs, structures.RunConfig{
-					Network:  rConf.Network,
-					ChainID:  rConf.ChainID,
-					Kind:     rConf.Kind,
-					TaskID:   rConf.TaskID,
-					Version:  "0.0.1",
-					Duration: duration,
-				})
-			}
-		}
 
-		if err := c.AddSchedules(ctx, rcs); err != nil {
-			logger.Fatal("Error adding schedules", zap.Error(err))
-			return
-		}
+	trgts, err := readDestinations(cfg)
+	if err != nil {
+		logger.Fatal("Error reading destinations config", zap.Error(err))
+		return
 	}
 
-	if cfg.DestinationsValue != "" {
-		logger.Info("[Scheduler] Loading destinations initial config from env var")
-
-		trgts := []structures.TargetConfig{}
-		dec := json.NewDecoder(strings.NewReader(cfg.DestinationsValue))
-		err = dec.Decode(&trgts)
-		if err != nil {
-			logger.Fatal("Error reading config from env (decode)", zap.Error(err), zap.String("cfg", cfg.DestinationsValue))
-			return
-		}
-
-		for _, trgt := range trgts {
-			err := cont.Add(ctx, trgt, connTray, scheme)
-			if err != nil {
-				logger.Error("Error adding destination", zap.Error(err))
-				return
-			}
-		}
-
-	} else if cfg.DestinationsConfig != "" {
-		logger.Info("[Scheduler] Loading destinations initial config from path")
-
-		files, err := ioutil.ReadDir(cfg.DestinationsConfig)
-		if err != nil {
-			logger.Fatal("Error reading scheduling config dir", zap.Error(err))
-			return
-		}
-
-		trgts := []structures.TargetConfig{}
-		for _, fileInfo := range files {
-			if fileInfo.IsDir() {
-				continue
-			}
-
-			file, err := os.Open(path.Join(cfg.DestinationsConfig, fileInfo.Name()))
-			if err != nil {
-				logger.Fatal("Error reading config file", zap.Error(err), zap.String("filepath", path.Join(cfg.DestinationsConfig, fileInfo.Name())))
-				return
-			}
-
-			dec := json.NewDecoder(file)
-			err = dec.Decode(&trgts)
-			file.Close()
-			if err != nil {
-				logger.Fatal("Error reading config file (decode)", zap.Error(err), zap.String("filepath", path.Join(cfg.DestinationsConfig, fileInfo.Name())))
-				return
-			}
-
-			for _, trgt := range trgts {
-				err := cont.Add(ctx, trgt, connTray, scheme)
-				if err != nil {
-					logger.Error("Error adding destination", zap.Error(err))
-					return
-				}
-			}
-		}
+	if err := cont.Sync(ctx, trgts, connTray, scheme); err != nil {
+		logger.Error("Error adding destination", zap.Error(err))
+		return
 	}
 
-	logger.Info("[Scheduler] Running Load")
-	go reloadScheduler(ctx, logger, c)
-
-	mux.Handle("/metrics", metrics.Handler())
-
 	pStore := runnerPersistence.NewLastDataStorageTransport(runnerDatabase.NewDriver(db))
 
 	lh := lastdata.NewClient(logger, pStore, creds, scheme)
@@ -291,10 +222,59 @@ func main() {
 
 	rsWS := runnerSyncrangeWS.NewSyncRangeWSTransport(logger, connTray)
 	sr.AddTransport(runnerWS.ConnectionTypeWS, rsWS)
+	sr.SetScheduleGetter(cStore)
 	sr.RegisterHandles(mux)
 
+	pCBStore := runnerCallbackPersistence.NewCallbackStorageTransport(runnerCallbackDatabase.NewDriver(db))
+	cb := callback.NewClient(logger, pCBStore, creds, scheme)
+	cb.AddTransport(runnerCallbackHTTP.ConnectionTypeHTTP, runnerCallbackHTTP.NewCallbackHTTPTransport(logger))
+	cb.AddTransport(runnerCallbackWS.ConnectionTypeWS, runnerCallbackWS.NewCallbackWSTransport(logger, connTray))
+	cb.RegisterHandles(mux)
+
+	pCLStore := runnerChainlagPersistence.NewChainLagStorageTransport(runnerChainlagDatabase.NewDriver(db))
+	cl := chainlag.NewClient(logger, pCLStore, creds, scheme, lh)
+	cl.AddTransport(runnerChainlagHTTP.ConnectionTypeHTTP, runnerChainlagHTTP.NewChainLagHTTPTransport(logger))
+	cl.AddTransport(runnerChainlagWS.ConnectionTypeWS, runnerChainlagWS.NewChainLagWSTransport(logger, connTray))
+	cl.RegisterHandles(mux)
+
+	pGDStore := runnerGapdetectPersistence.NewGapStorageTransport(runnerGapdetectDatabase.NewDriver(db))
+	gd := gapdetect.NewClient(logger, pGDStore, creds, lh, cStore)
+	gd.RegisterHandles(mux)
+
 	c.LoadRunner(lastdata.RunnerName, lh)
 	c.LoadRunner(syncrange.RunnerName, sr)
+	c.LoadRunner(callback.RunnerName, cb)
+	c.LoadRunner(chainlag.RunnerName, cl)
+	c.LoadRunner(gapdetect.RunnerName, gd)
+
+	plugins, err := loadPlugins(logger, cfg, c)
+	if err != nil {
+		logger.Fatal("Error loading plugins", zap.Error(err))
+		return
+	}
+
+	dg := core.NewDependencyGate()
+	dg.AddProvider(lastdata.RunnerName, lh)
+	dg.AddProvider(syncrange.RunnerName, sr)
+	dg.AddProvider(callback.RunnerName, cb)
+	sch.AddGate(dg)
+
+	if cfg.SchedulesConfig != "" {
+		logger.Info("[Scheduler] Reconciling schedules with config")
+		// schedules stored in the database keep running, the config is reconciled again on the next reload
+		if err := reconcileSchedules(ctx, logger, c, cfg.SchedulesConfig); err != nil {
+			logger.Warn("[Scheduler] Error reconciling schedules", zap.Error(err))
+		}
+	}
+
+	logger.Info("[Scheduler] Running Load")
+	go reloadScheduler(ctx, logger, c, mm)
+	go watchConfig(ctx, logger, cfg.ConfigReloadInterval, []string{cfg.SchedulesConfig, cfg.DestinationsConfig}, func() {
+		reloadConfig(ctx, logger, cfg, c, cont, connTray, scheme)
+	})
+	go heartbeat(ctx, logger, c, cfg.HeartbeatInterval)
+
+	mux.Handle("/metrics", metrics.Handler())
 
 	uInterface := ui.NewUI()
 	uInterface.RegisterHandles(mux)
@@ -307,7 +287,7 @@ func main() {
 		},
 	}
 
-	osSig := make(chan os.Signal)
+	osSig := make(chan os.Signal, 1)
 	exit := make(chan string, 2)
 	signal.Notify(osSig, syscall.SIGTERM)
 	signal.Notify(osSig, syscall.SIGINT)
@@ -319,6 +299,12 @@ RunLoop:
 		select {
 		case <-osSig:
 			s.Shutdown(ctx)
+			if err := c.Shutdown(ctx, cfg.ShutdownGracePeriod); err != nil {
+				logger.Error("[Scheduler] Error during shutdown", zap.Error(err))
+			}
+			for _, p := range plugins {
+				p.Close()
+			}
 			break RunLoop
 		case <-exit:
 			break RunLoop
@@ -335,15 +321,14 @@ func initConfig(path string) (config.Config, error) {
 		}
 	}
 
-	if cfg.DatabaseURL != "" {
-		return *cfg, nil
-	}
-
-	if err := config.FromEnv(cfg); err != nil {
-		return *cfg, err
+	if cfg.DatabaseURL == "" {
+		if err := config.FromEnv(cfg); err != nil {
+			return *cfg, err
+		}
 	}
 
-	return *cfg, nil
+	cfg.SetDefaults()
+	return *cfg, cfg.Validate()
 }
 
 func runHTTP(s *http.Server, address string, logger *zap.Logger, exit chan<- string) {
@@ -357,7 +342,7 @@ func runHTTP(s *http.Server, address string, logger *zap.Logger, exit chan<- str
 	exit <- "http"
 }
 
-func reloadScheduler(ctx context.Context, logger *zap.Logger, c *core.Core) {
+func reloadScheduler(ctx context.Context, logger *zap.Logger, c *core.Core, mm *maintenance.Manager) {
 	tckr := time.NewTicker(10 * time.Second)
 
 	for {
@@ -367,6 +352,28 @@ func reloadScheduler(ctx context.Context, logger *zap.Logger, c *core.Core) {
 				logger.Error("[Scheduler] Error during loading of scheduler", zap.Error(err))
 				logger.Sync()
 			}
+			// windows may be changed by other instances
+			if err := mm.Load(ctx); err != nil {
+				logger.Error("[Scheduler] Error during loading of maintenance windows", zap.Error(err))
+				logger.Sync()
+			}
+		}
+	}
+}
+
+func heartbeat(ctx context.Context, logger *zap.Logger, c *core.Core, interval time.Duration) {
+	tckr := time.NewTicker(interval)
+	defer tckr.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tckr.C:
+			if err := c.Heartbeat(ctx); err != nil {
+				logger.Error("[Scheduler] Error during heartbeat", zap.Error(err))
+				logger.Sync()
+			}
 		}
 	}
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/figment-networks/indexer-scheduler/cmd/scheduler/config"
+	"github.com/figment-networks/indexer-scheduler/core"
+	"github.com/figment-networks/indexer-scheduler/runner/plugin"
+	"github.com/figment-networks/indexer-scheduler/runner/plugin/structures"
+	"go.uber.org/zap"
+)
+
+// loadPlugins creates runners of plugins listed in the config file and loads them into core
+func loadPlugins(logger *zap.Logger, cfg config.Config, c *core.Core) (plugins []*plugin.Plugin, err error) {
+	if cfg.PluginsConfig == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(cfg.PluginsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plugins config file %s: %w", cfg.PluginsConfig, err)
+	}
+	defer file.Close()
+
+	pcs := []structures.PluginConfig{}
+	dec := json.NewDecoder(file)
+	if err := dec.Decode(&pcs); err != nil {
+		return nil, fmt.Errorf("error reading plugins config file (decode) %s: %w", cfg.PluginsConfig, err)
+	}
+
+	for _, pc := range pcs {
+		p, err := plugin.NewPlugin(logger, pc)
+		if err != nil {
+			return nil, fmt.Errorf("error creating plugin %s: %w", pc.Kind, err)
+		}
+		logger.Info("[Scheduler] Loading plugin", zap.String("kind", pc.Kind), zap.String("command", pc.Command))
+		c.LoadRunner(pc.Kind, p)
+		plugins = append(plugins, p)
+	}
+
+	return plugins, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/cmd/scheduler/config"
+	"github.com/figment-networks/indexer-scheduler/conn/tray"
+	"github.com/figment-networks/indexer-scheduler/core"
+	"github.com/figment-networks/indexer-scheduler/destination"
+	"go.uber.org/zap"
+)
+
+// watchConfig calls reload on SIGHUP and, if interval is set, every time content of any of dirs changes
+func watchConfig(ctx context.Context, logger *zap.Logger, interval time.Duration, dirs []string, reload func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		tckr := time.NewTicker(interval)
+		defer tckr.Stop()
+		tick = tckr.C
+	}
+
+	last := dirsFingerprint(dirs)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.Info("[Scheduler] SIGHUP received, reloading config")
+			last = dirsFingerprint(dirs)
+			reload()
+		case <-tick:
+			if fp := dirsFingerprint(dirs); fp != last {
+				logger.Info("[Scheduler] Config changed, reloading config")
+				last = fp
+				reload()
+			}
+		}
+	}
+}
+
+// dirsFingerprint describes the names, sizes and modification times of files in given directories
+func dirsFingerprint(dirs []string) string {
+	b := &strings.Builder{}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			fmt.Fprintf(b, "%s:error;", dir)
+			continue
+		}
+		for _, fi := range files {
+			if fi.IsDir() {
+				continue
+			}
+			// follow symlinks, so changes of mounted configmaps are noticed
+			if st, err := os.Stat(path.Join(dir, fi.Name())); err == nil {
+				fi = st
+			}
+			fmt.Fprintf(b, "%s/%s:%d:%d;", dir, fi.Name(), fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return b.String()
+}
+
+// reloadConfig applies current content of schedules and destinations config
+func reloadConfig(ctx context.Context, logger *zap.Logger, cfg config.Config, c *core.Core, cont *destination.Container, connTray *tray.ConnTray, scheme *destination.Scheme) {
+	defer logger.Sync()
+
+	trgts, err := readDestinations(cfg)
+	if err != nil {
+		logger.Error("[Scheduler] Error reading destinations config", zap.Error(err))
+	} else if err := cont.Sync(ctx, trgts, connTray, scheme); err != nil {
+		logger.Error("[Scheduler] Error reloading destinations", zap.Error(err))
+	}
+
+	if cfg.SchedulesConfig != "" {
+		if err := reconcileSchedules(ctx, logger, c, cfg.SchedulesConfig); err != nil {
+			logger.Error("[Scheduler] Error reconciling schedules", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/figment-networks/indexer-scheduler/core"
+	"go.uber.org/zap"
+)
+
+// readSchedules reads all the schedule definitions from given directory
+func readSchedules(dir string) (defs []core.ScheduleDefinition, err error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scheduling config dir: %w", err)
+	}
+
+	for _, fileInfo := range files {
+		if fileInfo.IsDir() {
+			continue
+		}
+
+		file, err := os.Open(path.Join(dir, fileInfo.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", path.Join(dir, fileInfo.Name()), err)
+		}
+
+		sd := []core.ScheduleDefinition{}
+		dec := json.NewDecoder(file)
+		err = dec.Decode(&sd)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file (decode) %s: %w", path.Join(dir, fileInfo.Name()), err)
+		}
+
+		defs = append(defs, sd...)
+	}
+
+	return defs, nil
+}
+
+// reconcileSchedules converges stored schedules with the definitions from dir
+func reconcileSchedules(ctx context.Context, logger *zap.Logger, c *core.Core, dir string) error {
+	defs, err := readSchedules(dir)
+	if err != nil {
+		return err
+	}
+
+	plan, err := c.PlanSchedules(ctx, defs)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("[Scheduler] Schedules reconciliation plan", zap.Int("changes", len(plan.Changes)))
+	return c.ApplySchedules(ctx, plan)
+}
+
+// printSchedulesPlan prints changes that reconciliation would make, without applying them
+func printSchedulesPlan(ctx context.Context, c *core.Core, dir string) error {
+	if dir == "" {
+		return errors.New("SCHEDULES_CONFIG is not set")
+	}
+
+	defs, err := readSchedules(dir)
+	if err != nil {
+		return err
+	}
+
+	plan, err := c.PlanSchedules(ctx, defs)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(plan)
+}
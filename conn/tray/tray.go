@@ -17,13 +17,18 @@ type PAKey struct {
 }
 
 type ConnTray struct {
-	logger *zap.Logger
-	l      sync.RWMutex
-	conns  map[PAKey]conn.RPCConnector
+	logger  *zap.Logger
+	l       sync.RWMutex
+	conns   map[PAKey]conn.RPCConnector
+	cancels map[PAKey]context.CancelFunc
 }
 
 func NewConnTray(logger *zap.Logger) *ConnTray {
-	return &ConnTray{logger: logger, conns: make(map[PAKey]conn.RPCConnector)}
+	return &ConnTray{
+		logger:  logger,
+		conns:   make(map[PAKey]conn.RPCConnector),
+		cancels: make(map[PAKey]context.CancelFunc),
+	}
 }
 
 func (c *ConnTray) Get(protocol, address string) (conn.RPCConnector, error) {
@@ -37,8 +42,10 @@ func (c *ConnTray) Get(protocol, address string) (conn.RPCConnector, error) {
 	switch protocol {
 	case "ws":
 		wsConn := ws.NewConn(c.logger)
-		go wsConn.Run(context.Background(), address, time.Minute*20)
+		ctx, cancel := context.WithCancel(context.Background())
+		go wsConn.Run(ctx, address, time.Minute*20)
 		c.conns[PAKey{protocol, address}] = wsConn
+		c.cancels[PAKey{protocol, address}] = cancel
 		return wsConn, nil
 	case "http": // todo implement http
 		return nil, errors.New("unknown protocol")
@@ -47,3 +54,17 @@ func (c *ConnTray) Get(protocol, address string) (conn.RPCConnector, error) {
 
 	}
 }
+
+// Close closes the connection and removes it from tray
+func (c *ConnTray) Close(protocol, address string) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	key := PAKey{protocol, address}
+	if cancel, ok := c.cancels[key]; ok {
+		c.logger.Info("[ConnTray] Closing connection", zap.String("protocol", protocol), zap.String("address", address))
+		cancel()
+		delete(c.cancels, key)
+	}
+	delete(c.conns, key)
+}
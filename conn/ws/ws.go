@@ -244,6 +244,10 @@ func (conn *Conn) Run(ctx context.Context, addr string, connTimeout time.Duratio
 	for {
 		select { // reconnects respecting context
 		case <-ctx.Done():
+			conn.statusLock.Lock()
+			conn.status[addr] = StateOffline
+			conn.statusEvenOne = StateOffline
+			conn.statusLock.Unlock()
 			return
 		case <-f:
 			conn.statusLock.Lock()
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/structures"
+	"github.com/google/uuid"
+)
+
+// BulkResult is the outcome of operation on a single schedule, selected by bulk operation
+type BulkResult struct {
+	ID      uuid.UUID `json:"id"`
+	Network string    `json:"network"`
+	ChainID string    `json:"chain_id"`
+	Kind    string    `json:"kind"`
+	TaskID  string    `json:"task_id"`
+
+	Status  string `json:"status"`
+	Backoff bool   `json:"backoff,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func newBulkResult(rc structures.RunConfig) BulkResult {
+	return BulkResult{ID: rc.ID, Network: rc.Network, ChainID: rc.ChainID, Kind: rc.Kind, TaskID: rc.TaskID}
+}
+
+// EnableSchedules enables all not archived schedules matching the selector
+func (c *Core) EnableSchedules(ctx context.Context, sel Selector) ([]BulkResult, error) {
+	rcs, err := c.ListSchedule(ctx, false, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, 0, len(rcs))
+	for _, rc := range rcs {
+		res := newBulkResult(rc)
+		switch err := c.enableLoaded(ctx, rc); {
+		case err != nil && !errors.Is(err, ErrAlreadyEnabled):
+			res.Status = "error"
+			res.Error = err.Error()
+		case err != nil || (rc.Enabled && rc.Status == structures.StateRunning):
+			res.Status = "unchanged"
+		default:
+			res.Status = "enabled"
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// enableLoaded enables the schedule read by ListSchedule, without reading all the configs again
+func (c *Core) enableLoaded(ctx context.Context, rc structures.RunConfig) error {
+	c.runLock.Lock()
+	defer c.runLock.Unlock()
+
+	if c.closing {
+		return ErrShuttingDown
+	}
+	return c.enableConfig(ctx, rc)
+}
+
+// DisableSchedules disables all not archived schedules matching the selector
+func (c *Core) DisableSchedules(ctx context.Context, sel Selector) ([]BulkResult, error) {
+	rcs, err := c.ListSchedule(ctx, false, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, 0, len(rcs))
+	for _, rc := range rcs {
+		res := newBulkResult(rc)
+		switch err := c.DisableSchedule(ctx, rc.ID); {
+		case errors.Is(err, ErrAlreadyDisabled):
+			res.Status = "unchanged"
+		case err != nil:
+			res.Status = "error"
+			res.Error = err.Error()
+		default:
+			res.Status = "disabled"
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// TriggerSchedules runs once every not archived schedule matching the selector.
+// Schedules are triggered one after another, so the workers are not flooded with requests.
+func (c *Core) TriggerSchedules(ctx context.Context, sel Selector) ([]BulkResult, error) {
+	rcs, err := c.ListSchedule(ctx, false, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, 0, len(rcs))
+	for _, rc := range rcs {
+		res := newBulkResult(rc)
+		backoff, err := c.TriggerSchedule(ctx, rc.ID)
+		res.Backoff = backoff
+		switch {
+		case err == io.EOF:
+			res.Status = "finished"
+		case err != nil:
+			res.Status = "error"
+			res.Error = err.Error()
+		default:
+			res.Status = "ok"
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (c *Core) handlerBulkEnableSchedules(w http.ResponseWriter, r *http.Request) {
+	c.handleBulk(w, r, c.EnableSchedules)
+}
+
+func (c *Core) handlerBulkDisableSchedules(w http.ResponseWriter, r *http.Request) {
+	c.handleBulk(w, r, c.DisableSchedules)
+}
+
+func (c *Core) handlerBulkTriggerSchedules(w http.ResponseWriter, r *http.Request) {
+	c.handleBulk(w, r, c.TriggerSchedules)
+}
+
+// handleBulk requires non empty selector, so the whole fleet cannot be changed by accident
+func (c *Core) handleBulk(w http.ResponseWriter, r *http.Request, op func(ctx context.Context, sel Selector) ([]BulkResult, error)) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	sel, err := ParseSelector(r.URL.Query().Get("selector"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	results, err := op(r.Context(), sel)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(results)
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/figment-networks/indexer-scheduler/persistence"
 	"github.com/figment-networks/indexer-scheduler/persistence/params"
 	"github.com/figment-networks/indexer-scheduler/process"
+	"github.com/figment-networks/indexer-scheduler/process/cron"
 	"github.com/figment-networks/indexer-scheduler/structures"
 	"go.uber.org/zap"
 
@@ -25,11 +28,20 @@ type MonitoredRunner interface {
 	RegisterHandles(mux *http.ServeMux)
 }
 
+// HistoryPurger is implemented by runners that keep the history of runs
+type HistoryPurger interface {
+	PurgeHistory(ctx context.Context, rcp structures.RunConfigParams) error
+}
+
 type Status string
 
 var (
 	ErrAlreadyEnabled  = errors.New("this schedule is already enabled")
 	ErrAlreadyDisabled = errors.New("this schedule is already disabled")
+	ErrLeaseHeld       = errors.New("this schedule is owned by other scheduler instance")
+	ErrArchived        = errors.New("this schedule is archived")
+	ErrNoSuchRunner    = errors.New("there is no such runner")
+	ErrShuttingDown    = errors.New("scheduler is shutting down")
 )
 
 type Core struct {
@@ -45,15 +57,24 @@ type Core struct {
 	scheduler *process.Scheduler
 
 	creds auth.AuthCredentials
+
+	leaseTTL time.Duration
+	// renewedAt is the start of the latest successful renewal of leases, it's used only by heartbeat
+	renewedAt time.Time
+
+	// closing is set on shutdown, schedules cannot be enabled after that
+	closing bool
 }
 
-func NewCore(store *persistence.CoreStorage, scheduler *process.Scheduler, creds auth.AuthCredentials, logger *zap.Logger) *Core {
+func NewCore(store *persistence.CoreStorage, scheduler *process.Scheduler, creds auth.AuthCredentials, leaseTTL time.Duration, logger *zap.Logger) *Core {
 	u, _ := uuid.NewRandom()
 	return &Core{
 		ID:        u,
 		coreStore: store,
 		scheduler: scheduler,
 		logger:    logger,
+		leaseTTL:  leaseTTL,
+		renewedAt: time.Now(),
 
 		run:     map[uuid.UUID]structures.RunConfig{},
 		runners: map[string]MonitoredRunner{},
@@ -94,6 +115,9 @@ func (c *Core) InitialLoad(ctx context.Context) error {
 	return c.coreStore.RemoveStatusAllEnabled(ctx)
 }
 
+// LoadScheduler synchronizes locally running schedules with the database.
+// Enabled schedules that are not held by any live instance are taken over,
+// local ones that were disabled or taken over by other instance are stopped.
 func (c *Core) LoadScheduler(ctx context.Context) error {
 	defer c.logger.Sync()
 
@@ -102,34 +126,146 @@ func (c *Core) LoadScheduler(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	present := make(map[uuid.UUID]struct{}, len(rcs))
 	for _, s := range rcs {
+		present[s.ID] = struct{}{}
 		c.runLock.Lock()
-		r, ok := c.run[s.ID]
-		if !ok {
-			c.run[s.ID] = s
-		}
+		c.run[s.ID] = s
 		c.runLock.Unlock()
 
-		if r.Enabled && (r.Status != structures.StateFinished && r.Status != structures.StateStopped) {
-			if err := c.EnableSchedule(ctx, s.ID); err != nil {
-				return fmt.Errorf("error running enableSchedule %w", err)
+		if rc, ok := c.scheduler.RunningConfig(s.ID); ok {
+			if !s.Enabled || s.RunID != c.ID {
+				c.logger.Info("[Core] Stopping schedule, it's disabled or owned by other instance", zap.String("id", s.ID.String()), zap.String("run_id", s.RunID.String()))
+				c.scheduler.Stop(ctx, s.ID)
+			} else if rc.Revision != s.Revision {
+				c.logger.Info("[Core] Restarting schedule, config was updated", zap.String("id", s.ID.String()), zap.Uint64("revision", s.Revision))
+				if err := c.restartSchedule(s); err != nil {
+					return fmt.Errorf("error restarting schedule %w", err)
+				}
 			}
+			continue
+		}
+
+		if !s.Enabled || s.Status == structures.StateFinished || s.Status == structures.StateStopped || s.Status == structures.StateArchived || s.Status == structures.StateFailed {
+			continue
+		}
+
+		if s.Status == structures.StateRunning && (s.RunID == c.ID || s.LeaseExpires.After(time.Now())) {
+			continue
 		}
 
+		switch err := c.EnableSchedule(ctx, s.ID); {
+		case errors.Is(err, ErrShuttingDown):
+			return nil
+		case err != nil && !errors.Is(err, ErrLeaseHeld):
+			return fmt.Errorf("error running enableSchedule %w", err)
+		}
+	}
+
+	// stop schedules that were deleted
+	for _, id := range c.scheduler.RunningIDs() {
+		if _, ok := present[id]; !ok {
+			c.logger.Info("[Core] Stopping schedule, it was removed", zap.String("id", id.String()))
+			c.scheduler.Stop(ctx, id)
+		}
 	}
 
 	return nil
 }
 
-func (c *Core) ListSchedule(ctx context.Context) ([]structures.RunConfig, error) {
+// Heartbeat renews leases of locally running schedules, stopping the ones that were lost.
+// When leases could not be renewed for longer than their TTL, all the local schedules are stopped,
+// as other instances may have taken them over already.
+func (c *Core) Heartbeat(ctx context.Context) error {
+	now := time.Now()
+	ids := c.scheduler.RunningIDs()
+	if len(ids) == 0 {
+		c.renewedAt = now
+		return nil
+	}
+
+	renewed, err := c.coreStore.RenewLeases(ctx, c.ID, ids, c.leaseTTL)
+	if err != nil {
+		if time.Since(c.renewedAt) > c.leaseTTL {
+			c.logger.Error("[Core] Leases expired without renewal, stopping all schedules", zap.Time("renewed_at", c.renewedAt), zap.Error(err))
+			for _, id := range ids {
+				c.scheduler.Stop(ctx, id)
+			}
+		}
+		return fmt.Errorf("error renewing leases: %w", err)
+	}
+	c.renewedAt = now
+
+	owned := make(map[uuid.UUID]struct{}, len(renewed))
+	for _, id := range renewed {
+		owned[id] = struct{}{}
+	}
+
+	for _, id := range ids {
+		if _, ok := owned[id]; !ok {
+			c.logger.Warn("[Core] Lease lost, stopping schedule", zap.String("id", id.String()))
+			c.scheduler.Stop(ctx, id)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops all the schedules of this instance, giving in-flight runs grace period to finish.
+// Schedules are left enabled, with released leases, so they're immediately picked up by the next instance.
+func (c *Core) Shutdown(ctx context.Context, grace time.Duration) error {
+	c.runLock.Lock()
+	defer c.runLock.Unlock()
+
+	c.closing = true
+
+	c.logger.Info("[Core] Shutting down, waiting for in-flight runs", zap.Duration("grace_period", grace))
+	gCtx, cancel := context.WithTimeout(ctx, grace)
+	stopped := c.scheduler.Shutdown(gCtx)
+	cancel()
+
+	if err := c.coreStore.MarkReleased(ctx, c.ID, stopped); err != nil {
+		return fmt.Errorf("error releasing schedules: %w", err)
+	}
+
+	for _, id := range stopped {
+		if r, ok := c.run[id]; ok {
+			r.Status = structures.StateAdded
+			c.run[id] = r
+		}
+	}
+
+	c.logger.Info("[Core] Shutdown finished", zap.Int("released", len(stopped)))
+	return nil
+}
+
+// ListSchedule lists schedules matching the selector, nil selector matches everything
+func (c *Core) ListSchedule(ctx context.Context, includeArchived bool, sel Selector) ([]structures.RunConfig, error) {
 	rcs, err := c.coreStore.GetConfigs(ctx)
-	return rcs, err
+	if err != nil {
+		return rcs, err
+	}
+
+	list := []structures.RunConfig{}
+	for _, rc := range rcs {
+		if !includeArchived && rc.Status == structures.StateArchived {
+			continue
+		}
+		if sel.Matches(rc) {
+			list = append(list, rc)
+		}
+	}
+	return list, nil
 }
 
 func (c *Core) EnableSchedule(ctx context.Context, sID uuid.UUID) error {
 	c.runLock.Lock()
 	defer c.runLock.Unlock()
 
+	if c.closing {
+		return ErrShuttingDown
+	}
+
 	rcs, err := c.coreStore.GetConfigs(ctx)
 	if err != nil {
 		return fmt.Errorf("error getting config %w", err)
@@ -145,31 +281,53 @@ func (c *Core) EnableSchedule(ctx context.Context, sID uuid.UUID) error {
 		return fmt.Errorf("there is no such schedule ('%s') to enable", sID)
 	}
 
-	if r.Enabled && r.Status == structures.StateRunning {
+	return c.enableConfig(ctx, r)
+}
+
+// enableConfig starts already loaded schedule, the caller has to hold runLock
+func (c *Core) enableConfig(ctx context.Context, r structures.RunConfig) error {
+	sID := r.ID
+	if c.scheduler.IsRunning(sID) {
 		return nil
 		// return ErrAlreadyEnabled
 	}
 
+	if r.Status == structures.StateArchived {
+		return ErrArchived
+	}
+
 	runner, ok := c.runners[r.Kind]
 	if !ok {
 		return fmt.Errorf("there is no such runner: %s", r.Kind)
 	}
 
-	c.logger.Info(fmt.Sprintf("[Core] Running schedule %s (%s:%s) %s in %s", runner.Name(), r.Network, r.ChainID, r.Version, r.Duration.String()))
-	go c.scheduler.Run(context.Background(), sID, r.Duration,
-		structures.RunConfigParams{
-			Network: r.Network,
-			ChainID: r.ChainID,
-			TaskID:  r.TaskID,
-			Version: r.Version,
-			Config:  r.Config,
-			Kind:    r.Kind},
-		runner)
+	if _, err := process.NewSchedule(r); err != nil {
+		return fmt.Errorf("error creating schedule for %s: %w", sID, err)
+	}
+
+	acquired, err := c.coreStore.AcquireLease(ctx, c.ID, sID, c.leaseTTL)
+	if err != nil {
+		return fmt.Errorf("error acquiring lease %w", err)
+	}
+	if !acquired {
+		if r.Enabled {
+			return nil
+		}
+		return ErrLeaseHeld
+	}
+	r.RunID = c.ID
 
+	// state is set before starting, so the final state set by schedule is not overwritten
 	if err := c.coreStore.MarkRunning(ctx, c.ID, sID); err != nil {
-		c.logger.Error("[Core] Error setting state running", zap.Error(err))
+		if err2 := c.coreStore.ReleaseLease(ctx, c.ID, sID); err2 != nil {
+			c.logger.Error("[Core] Error releasing lease", zap.String("id", sID.String()), zap.Error(err2))
+		}
+		return fmt.Errorf("error setting state running: %w", err)
 	}
 
+	c.logger.Info(fmt.Sprintf("[Core] Running schedule %s (%s:%s) %s in %s %s", runner.Name(), r.Network, r.ChainID, r.Version, r.Duration.String(), r.Cron))
+	go c.scheduler.Run(context.Background(), r, runner)
+
 	r.Enabled = true
 	r.Status = structures.StateRunning
 	c.run[sID] = r
@@ -214,11 +372,236 @@ func (c *Core) DisableSchedule(ctx context.Context, sID uuid.UUID) error {
 
 }
 
+// TriggerSchedule runs the schedule once, immediately. It works regardless of schedule being enabled, except archived ones.
+// Schedule running locally is never run in parallel with its scheduled runs. Otherwise the lease is taken for the time of the run,
+// so no instance starts the schedule meanwhile, and schedule owned by other live instance has to be triggered there.
+func (c *Core) TriggerSchedule(ctx context.Context, sID uuid.UUID) (backoff bool, err error) {
+	r, err := c.getConfig(ctx, sID)
+	if err != nil {
+		return false, err
+	}
+
+	if r.Status == structures.StateArchived {
+		return false, ErrArchived
+	}
+
+	c.runLock.RLock()
+	runner, ok := c.runners[r.Kind]
+	c.runLock.RUnlock()
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrNoSuchRunner, r.Kind)
+	}
+
+	if c.scheduler.IsRunning(sID) {
+		return c.scheduler.RunOnce(ctx, r, runner)
+	}
+
+	// the lease is taken by id of this run, so the schedule is not started even by this instance
+	triggerID, _ := uuid.NewRandom()
+	acquired, err := c.coreStore.AcquireLease(ctx, triggerID, sID, c.leaseTTL)
+	if err != nil {
+		return false, fmt.Errorf("error acquiring lease: %w", err)
+	}
+	if !acquired {
+		if c.scheduler.IsRunning(sID) {
+			return c.scheduler.RunOnce(ctx, r, runner)
+		}
+		return false, ErrLeaseHeld
+	}
+
+	rCtx, cancel := context.WithCancel(ctx)
+	held := make(chan struct{})
+	go func() {
+		c.holdLease(rCtx, cancel, triggerID, sID)
+		close(held)
+	}()
+	defer func() {
+		cancel()
+		<-held
+		sCtx, sCancel := structures.StoreContext()
+		defer sCancel()
+		if err := c.coreStore.ReleaseLease(sCtx, triggerID, sID); err != nil {
+			c.logger.Error("[Core] Error releasing lease of triggered run", zap.String("id", sID.String()), zap.Error(err))
+		}
+	}()
+
+	return c.scheduler.RunOnce(rCtx, r, runner)
+}
+
+// holdLease renews the lease of triggered run until ctx is done. The run is cancelled once the lease cannot be held anymore.
+func (c *Core) holdLease(ctx context.Context, cancel context.CancelFunc, runID, sID uuid.UUID) {
+	ticker := time.NewTicker(c.leaseTTL / 3)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			acquired, err := c.coreStore.AcquireLease(ctx, runID, sID, c.leaseTTL)
+			if err == nil && acquired {
+				renewedAt = now
+				continue
+			}
+			if err == nil || time.Since(renewedAt) > c.leaseTTL {
+				c.logger.Warn("[Core] Lease of triggered run lost, cancelling run", zap.String("id", sID.String()), zap.Error(err))
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// ArchiveSchedule stops and disables schedule, keeping it in the database.
+// Archived schedules are not listed by default and cannot be enabled again.
+func (c *Core) ArchiveSchedule(ctx context.Context, sID uuid.UUID, purge bool) error {
+	c.runLock.Lock()
+	defer c.runLock.Unlock()
+
+	r, err := c.getConfig(ctx, sID)
+	if err != nil {
+		return err
+	}
+
+	c.scheduler.Stop(ctx, sID)
+
+	if err := c.coreStore.MarkArchived(ctx, sID); err != nil {
+		return fmt.Errorf("error setting state archived: %w", err)
+	}
+
+	r.Enabled = false
+	r.Status = structures.StateArchived
+	c.run[sID] = r
+
+	if purge {
+		return c.purgeHistory(ctx, r)
+	}
+	return nil
+}
+
+// DeleteSchedule stops schedule and removes it from the database
+func (c *Core) DeleteSchedule(ctx context.Context, sID uuid.UUID, purge bool) error {
+	c.runLock.Lock()
+	defer c.runLock.Unlock()
+
+	r, err := c.getConfig(ctx, sID)
+	if err != nil {
+		return err
+	}
+
+	c.scheduler.Stop(ctx, sID)
+
+	// history is purged first, so failed purge can be retried while the schedule still exists
+	if purge {
+		if err := c.purgeHistory(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	if err := c.coreStore.DeleteConfig(ctx, sID); err != nil {
+		return fmt.Errorf("error deleting config: %w", err)
+	}
+	delete(c.run, sID)
+	return nil
+}
+
+// purgeHistory removes history of runs from runner persistence. runLock has to be held.
+func (c *Core) purgeHistory(ctx context.Context, r structures.RunConfig) error {
+	runner, ok := c.runners[r.Kind]
+	if !ok {
+		return fmt.Errorf("there is no such runner: %s", r.Kind)
+	}
+
+	hp, ok := runner.(HistoryPurger)
+	if !ok {
+		return nil
+	}
+
+	c.logger.Info("[Core] Purging history of schedule", zap.String("id", r.ID.String()), zap.String("kind", r.Kind), zap.String("network", r.Network), zap.String("chain_id", r.ChainID), zap.String("task_id", r.TaskID))
+	if err := hp.PurgeHistory(ctx, r.Params()); err != nil {
+		return fmt.Errorf("error purging history: %w", err)
+	}
+	return nil
+}
+
+// UpdateSchedule stores the new parameters of schedule. Update is rejected if
+// the schedule was modified since rc was read (revision mismatch).
+// Running schedule is restarted, so new parameters take effect immediately.
+func (c *Core) UpdateSchedule(ctx context.Context, rc structures.RunConfig) error {
+	c.runLock.Lock()
+
+	if _, err := process.NewSchedule(rc); err != nil {
+		c.runLock.Unlock()
+		return fmt.Errorf("error creating schedule for %s: %w", rc.ID, err)
+	}
+
+	if err := c.coreStore.UpdateConfig(ctx, rc); err != nil {
+		c.runLock.Unlock()
+		return fmt.Errorf("error updating config: %w", err)
+	}
+	rc.Revision++
+	// updated schedule starts over, as its timing might have changed
+	rc.NextRun = time.Time{}
+	rc.BackoffIteration = 0
+	c.run[rc.ID] = rc
+	c.runLock.Unlock()
+
+	if !c.scheduler.IsRunning(rc.ID) {
+		return nil
+	}
+
+	return c.restartSchedule(rc)
+}
+
+// restartSchedule stops locally running schedule and starts it again with given config. runLock must not be held,
+// as the old run loop is awaited until it finishes its in-flight run, no matter if the caller's request is done.
+func (c *Core) restartSchedule(rc structures.RunConfig) error {
+	c.runLock.Lock()
+	runner, ok := c.runners[rc.Kind]
+	c.runLock.Unlock()
+	if !ok {
+		return fmt.Errorf("there is no such runner: %s", rc.Kind)
+	}
+
+	c.scheduler.Stop(context.Background(), rc.ID)
+
+	// schedule running here is owned by this instance
+	rc.RunID = c.ID
+	c.logger.Info(fmt.Sprintf("[Core] Restarting schedule %s (%s:%s) %s in %s %s", runner.Name(), rc.Network, rc.ChainID, rc.Version, rc.Duration.String(), rc.Cron))
+	go c.scheduler.Run(context.Background(), rc, runner)
+
+	return nil
+}
+
+func (c *Core) getConfig(ctx context.Context, sID uuid.UUID) (rc structures.RunConfig, err error) {
+	rcs, err := c.coreStore.GetConfigs(ctx)
+	if err != nil {
+		return rc, fmt.Errorf("error getting config %w", err)
+	}
+
+	for _, rconf := range rcs {
+		if rconf.ID == sID {
+			return rconf, nil
+		}
+	}
+
+	return rc, params.ErrNotFound
+}
+
 func (c *Core) RegisterHandles(smux *http.ServeMux) {
 	smux.HandleFunc("/scheduler/core/list", c.handlerListSchedule)
 	smux.HandleFunc("/scheduler/core/enable/", c.handlerEnableSchedule)
 	smux.HandleFunc("/scheduler/core/disable/", c.handlerDisableSchedule)
 	smux.HandleFunc("/scheduler/core/addTask/", c.handlerAddSchedule)
+	smux.HandleFunc("/scheduler/core/update/", c.handlerUpdateSchedule)
+	smux.HandleFunc("/scheduler/core/archive/", c.handlerArchiveSchedule)
+	smux.HandleFunc("/scheduler/core/delete/", c.handlerDeleteSchedule)
+	smux.HandleFunc("/scheduler/core/trigger/", c.handlerTriggerSchedule)
+	smux.HandleFunc("/scheduler/core/skipped", c.handlerSkippedSchedules)
+	smux.HandleFunc("/scheduler/core/bulk/enable", c.handlerBulkEnableSchedules)
+	smux.HandleFunc("/scheduler/core/bulk/disable", c.handlerBulkDisableSchedules)
+	smux.HandleFunc("/scheduler/core/bulk/trigger", c.handlerBulkTriggerSchedules)
 }
 
 func (c *Core) handlerListSchedule(w http.ResponseWriter, r *http.Request) {
@@ -229,7 +612,17 @@ func (c *Core) handlerListSchedule(w http.ResponseWriter, r *http.Request) {
 	enc := json.NewEncoder(w)
 	w.Header().Add("Content-type", "application/json")
 
-	schedule, err := c.ListSchedule(r.Context())
+	var sel Selector
+	if selector := r.URL.Query().Get("selector"); selector != "" {
+		var err error
+		if sel, err = ParseSelector(selector); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+	}
+
+	schedule, err := c.ListSchedule(r.Context(), r.URL.Query().Get("archived") == "true", sel)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		enc.Encode([]byte(`{"error":"` + err.Error() + `"}`))
@@ -240,6 +633,18 @@ func (c *Core) handlerListSchedule(w http.ResponseWriter, r *http.Request) {
 	enc.Encode(schedule)
 }
 
+func (c *Core) handlerSkippedSchedules(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(c.scheduler.Skipped())
+}
+
 func (c *Core) handlerEnableSchedule(w http.ResponseWriter, r *http.Request) {
 	if err := auth.BasicAuth(c.creds, w, r); err != nil {
 		return
@@ -289,14 +694,124 @@ func (c *Core) handlerDisableSchedule(w http.ResponseWriter, r *http.Request) {
 	enc.Encode(string(`{"status":"ok"}`))
 }
 
+func (c *Core) handlerArchiveSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	sIDs := strings.Replace(r.URL.Path, "/scheduler/core/archive/", "", -1)
+	sID, err := uuid.Parse(sIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if err := c.ArchiveSchedule(r.Context(), sID, r.URL.Query().Get("purge") == "true"); err != nil {
+		if errors.Is(err, params.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(string(`{"status":"ok"}`))
+}
+
+func (c *Core) handlerDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	sIDs := strings.Replace(r.URL.Path, "/scheduler/core/delete/", "", -1)
+	sID, err := uuid.Parse(sIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if err := c.DeleteSchedule(r.Context(), sID, r.URL.Query().Get("purge") == "true"); err != nil {
+		if errors.Is(err, params.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(string(`{"status":"ok"}`))
+}
+
+func (c *Core) handlerTriggerSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	sIDs := strings.Replace(r.URL.Path, "/scheduler/core/trigger/", "", -1)
+	sID, err := uuid.Parse(sIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	backoff, err := c.TriggerSchedule(r.Context(), sID)
+	switch {
+	case err == io.EOF:
+		w.WriteHeader(http.StatusOK)
+		enc.Encode(string(`{"status":"finished"}`))
+	case errors.Is(err, params.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+	case errors.Is(err, ErrArchived), errors.Is(err, ErrLeaseHeld):
+		w.WriteHeader(http.StatusConflict)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		enc.Encode(string(`{"error":"` + err.Error() + `","backoff":` + strconv.FormatBool(backoff) + `}`))
+	default:
+		w.WriteHeader(http.StatusOK)
+		enc.Encode(string(`{"status":"ok","backoff":` + strconv.FormatBool(backoff) + `}`))
+	}
+}
+
 type RunConfigAddRequest struct {
 	Network  string `json:"network"`
 	ChainID  string `json:"chain_id"`
 	TaskID   string `json:"task_id"`
 	Interval string `json:"interval"`
+	Cron     string `json:"cron"`
 	Kind     string `json:"kind"`
 
 	Config map[string]interface{} `json:"config"`
+	Labels map[string]string      `json:"labels"`
+
+	Timeout     string                  `json:"timeout"`
+	RetryPolicy *structures.RetryPolicy `json:"retry_policy"`
+
+	// StartAt and EndAt are optional RFC3339 timestamps
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+
+	StartOffset string `json:"start_offset"`
+	Jitter      string `json:"jitter"`
+
+	Dependencies []structures.Dependency `json:"dependencies"`
+
+	MissedRuns structures.MissedRunPolicy `json:"missed_runs"`
 }
 
 func (c *Core) handlerAddSchedule(w http.ResponseWriter, r *http.Request) {
@@ -317,30 +832,103 @@ func (c *Core) handlerAddSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if rcar.Network == "" || rcar.ChainID == "" ||
-		rcar.TaskID == "" || rcar.Interval == "" {
+		rcar.TaskID == "" || (rcar.Interval == "" && rcar.Cron == "") {
 		w.WriteHeader(http.StatusBadRequest)
 		enc.Encode(string(`{"error": "all parameters are required"}`))
 		return
 
 	}
 
-	interval, err := time.ParseDuration(rcar.Interval)
+	var interval time.Duration
+	if rcar.Interval != "" {
+		var err error
+		if interval, err = time.ParseDuration(rcar.Interval); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+	}
+
+	if rcar.Cron != "" {
+		if _, err := cron.Parse(rcar.Cron); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+	}
+
+	var timeout time.Duration
+	if rcar.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(rcar.Timeout); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+	}
+
+	startOffset, err := parseOptionalDuration(rcar.StartOffset)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	jitter, err := parseOptionalDuration(rcar.Jitter)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
 		return
 	}
 
+	if rcar.RetryPolicy != nil {
+		if err := rcar.RetryPolicy.Validate(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+	}
+
 	runConfig := structures.RunConfig{
 		Network:  rcar.Network,
 		ChainID:  rcar.ChainID,
 		Version:  "0.0.1",
 		TaskID:   rcar.TaskID,
 		Duration: interval,
+		Cron:     rcar.Cron,
 		Kind:     rcar.Kind,
 		Enabled:  false,
 		Config:   rcar.Config,
+		Labels:   rcar.Labels,
 		Status:   structures.StateAdded,
+
+		Timeout:     timeout,
+		RetryPolicy: rcar.RetryPolicy,
+		StartAt:     rcar.StartAt,
+		EndAt:       rcar.EndAt,
+		StartOffset: startOffset,
+		Jitter:      jitter,
+
+		Dependencies: rcar.Dependencies,
+		MissedRuns:   rcar.MissedRuns,
+	}
+
+	if err := runConfig.MissedRuns.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if err := validateDependencies(runConfig.Dependencies); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if err := runConfig.ValidateWindow(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
 	}
 
 	if err := c.coreStore.AddConfig(r.Context(), runConfig); err != nil {
@@ -351,3 +939,201 @@ func (c *Core) handlerAddSchedule(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	enc.Encode(string(`{"status":"ok"}`))
 }
+
+type RunConfigUpdateRequest struct {
+	Interval *string `json:"interval"`
+	Cron     *string `json:"cron"`
+	Version  *string `json:"version"`
+
+	Config map[string]interface{} `json:"config"`
+	Labels map[string]string      `json:"labels"`
+
+	// Timeout of a single run, empty string removes the limit
+	Timeout *string `json:"timeout"`
+
+	// StartAt and EndAt replace the activity period, zero time removes the bound
+	StartAt *time.Time `json:"start_at"`
+	EndAt   *time.Time `json:"end_at"`
+
+	// Dependencies replace the current ones, empty list removes them
+	Dependencies []structures.Dependency `json:"dependencies"`
+
+	// StartOffset and Jitter replace the current values, empty string removes them
+	StartOffset *string `json:"start_offset"`
+	Jitter      *string `json:"jitter"`
+
+	// RetryPolicy replaces the current policy, empty object removes it
+	RetryPolicy *structures.RetryPolicy `json:"retry_policy"`
+
+	MissedRuns *structures.MissedRunPolicy `json:"missed_runs"`
+
+	Revision uint64 `json:"revision"`
+}
+
+func (c *Core) handlerUpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	sIDs := strings.Replace(r.URL.Path, "/scheduler/core/update/", "", -1)
+	sID, err := uuid.Parse(sIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	rcur := RunConfigUpdateRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&rcur); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	rc, err := c.getConfig(r.Context(), sID)
+	if err != nil {
+		if errors.Is(err, params.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if rcur.Interval != nil {
+		rc.Duration = 0
+		if *rcur.Interval != "" {
+			if rc.Duration, err = time.ParseDuration(*rcur.Interval); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+				return
+			}
+		}
+	}
+	if rcur.Cron != nil {
+		rc.Cron = *rcur.Cron
+	}
+	if rcur.Version != nil && *rcur.Version != "" {
+		rc.Version = *rcur.Version
+	}
+	if rcur.Config != nil {
+		rc.Config = rcur.Config
+	}
+	if rcur.Labels != nil {
+		rc.Labels = rcur.Labels
+	}
+	if rcur.Timeout != nil {
+		rc.Timeout = 0
+		if *rcur.Timeout != "" {
+			if rc.Timeout, err = time.ParseDuration(*rcur.Timeout); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+				return
+			}
+		}
+	}
+	if rcur.Dependencies != nil {
+		if err := validateDependencies(rcur.Dependencies); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+		rc.Dependencies = rcur.Dependencies
+	}
+	if rcur.StartOffset != nil {
+		if rc.StartOffset, err = parseOptionalDuration(*rcur.StartOffset); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+	}
+	if rcur.Jitter != nil {
+		if rc.Jitter, err = parseOptionalDuration(*rcur.Jitter); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+	}
+	if rcur.StartAt != nil {
+		rc.StartAt = *rcur.StartAt
+	}
+	if rcur.EndAt != nil {
+		rc.EndAt = *rcur.EndAt
+	}
+	if err := rc.ValidateWindow(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+	if rcur.RetryPolicy != nil {
+		if err := rcur.RetryPolicy.Validate(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+		rc.RetryPolicy = rcur.RetryPolicy
+		if *rcur.RetryPolicy == (structures.RetryPolicy{}) {
+			rc.RetryPolicy = nil
+		}
+	}
+	if rcur.MissedRuns != nil {
+		if err := rcur.MissedRuns.Validate(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+		rc.MissedRuns = *rcur.MissedRuns
+	}
+	rc.Revision = rcur.Revision
+
+	if _, err := process.NewSchedule(rc); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if err := c.UpdateSchedule(r.Context(), rc); err != nil {
+		switch {
+		case errors.Is(err, params.ErrRevisionMismatch):
+			w.WriteHeader(http.StatusConflict)
+		case errors.Is(err, params.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(string(`{"status":"ok","revision":` + strconv.FormatUint(rc.Revision+1, 10) + `}`))
+}
+
+// parseOptionalDuration parses non negative duration, empty string means zero
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration cannot be negative: %s", s)
+	}
+	return d, nil
+}
+
+func validateDependencies(deps []structures.Dependency) error {
+	for _, d := range deps {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
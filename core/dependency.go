@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/structures"
+)
+
+// dependencyCheckTimeout limits reading the state of dependencies before the run
+const dependencyCheckTimeout = 10 * time.Second
+
+// StatusProvider is implemented by runners, that are able to report the state of the latest run
+type StatusProvider interface {
+	LatestStatus(ctx context.Context, rcp structures.RunConfigParams) (structures.LatestStatus, error)
+}
+
+// DependencyGate skips the runs of schedules, that have dependencies not met
+type DependencyGate struct {
+	providers     map[string]StatusProvider
+	providersLock sync.RWMutex
+}
+
+func NewDependencyGate() *DependencyGate {
+	return &DependencyGate{
+		providers: make(map[string]StatusProvider),
+	}
+}
+
+func (dg *DependencyGate) AddProvider(kind string, sp StatusProvider) {
+	dg.providersLock.Lock()
+	defer dg.providersLock.Unlock()
+
+	dg.providers[kind] = sp
+}
+
+// Skip implements process.Gate
+func (dg *DependencyGate) Skip(rc structures.RunConfig, t time.Time) (skip bool, reason string) {
+	if len(rc.Dependencies) == 0 {
+		return false, ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dependencyCheckTimeout)
+	defer cancel()
+
+	rcp := rc.Params()
+	for _, d := range rc.Dependencies {
+		if err := dg.check(ctx, d.Params(rcp), d); err != nil {
+			return true, err.Error()
+		}
+	}
+	return false, ""
+}
+
+func (dg *DependencyGate) check(ctx context.Context, dp structures.RunConfigParams, d structures.Dependency) error {
+	name := fmt.Sprintf("%s/%s (%s:%s)", dp.Kind, dp.TaskID, dp.Network, dp.ChainID)
+
+	dg.providersLock.RLock()
+	sp, ok := dg.providers[dp.Kind]
+	dg.providersLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("dependency %s cannot be checked: runner does not report its status", name)
+	}
+
+	status, err := sp.LatestStatus(ctx, dp)
+	if err != nil {
+		if errors.Is(err, params.ErrNotFound) {
+			return fmt.Errorf("dependency %s has not run yet", name)
+		}
+		return fmt.Errorf("error checking dependency %s: %w", name, err)
+	}
+
+	if d.Succeeded && !status.Succeeded {
+		return fmt.Errorf("latest run of dependency %s at %s has failed", name, status.Time.Format(time.RFC3339))
+	}
+
+	if status.Height < d.MinHeight {
+		return fmt.Errorf("dependency %s is at height %d, waiting for %d", name, status.Height, d.MinHeight)
+	}
+
+	return nil
+}
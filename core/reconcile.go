@@ -0,0 +1,443 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/process"
+	"github.com/figment-networks/indexer-scheduler/structures"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const DefaultVersion = "0.0.1"
+
+// ManagedLabel marks schedules created or updated from the schedules config.
+// Only such schedules are disabled when they disappear from the config.
+const (
+	ManagedLabel      = "managed_by"
+	ManagedLabelValue = "schedules_config"
+)
+
+// ScheduleDefinition is a single schedule of the schedules config. Durations are written like `30s`.
+// Kind, network, chain_id and task_id are required, all the other fields are optional.
+type ScheduleDefinition struct {
+	Network  string `json:"network"`
+	ChainID  string `json:"chain_id"`
+	Version  string `json:"version"`
+	Kind     string `json:"kind"`
+	TaskID   string `json:"task_id"`
+	Interval string `json:"interval"`
+	Cron     string `json:"cron"`
+
+	Config map[string]interface{} `json:"config"`
+	Labels map[string]string      `json:"labels,omitempty"`
+
+	// Timeout of a single run
+	Timeout string `json:"timeout,omitempty"`
+
+	StartAt *time.Time `json:"start_at,omitempty"`
+	EndAt   *time.Time `json:"end_at,omitempty"`
+
+	StartOffset string `json:"start_offset,omitempty"`
+	Jitter      string `json:"jitter,omitempty"`
+
+	RetryPolicy *structures.RetryPolicy `json:"retry_policy,omitempty"`
+
+	Dependencies []structures.Dependency `json:"dependencies,omitempty"`
+
+	// MissedRuns policy: `skip`, `once` or `all`
+	MissedRuns structures.MissedRunPolicy `json:"missed_runs,omitempty"`
+
+	// Enabled is the desired state of schedule, nil leaves it intact
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type ReconcileAction string
+
+const (
+	ReconcileCreate  ReconcileAction = "create"
+	ReconcileUpdate  ReconcileAction = "update"
+	ReconcileDisable ReconcileAction = "disable"
+	// ReconcileSkip reports defined schedule that is archived, archived schedules are not brought back
+	ReconcileSkip ReconcileAction = "skip"
+)
+
+// ReconcileChange is a single change needed to converge the database with desired state
+type ReconcileChange struct {
+	Action ReconcileAction `json:"action"`
+	ID     uuid.UUID       `json:"id,omitempty"`
+
+	Network string `json:"network"`
+	ChainID string `json:"chain_id"`
+	Kind    string `json:"kind"`
+	TaskID  string `json:"task_id"`
+
+	// Diff lists the changed fields in form of `field: current -> desired`
+	Diff []string `json:"diff,omitempty"`
+
+	desired       structures.RunConfig
+	manageEnabled bool
+}
+
+// ReconcilePlan is a list of changes, that may be reviewed before applying
+type ReconcilePlan struct {
+	Changes []ReconcileChange `json:"changes"`
+}
+
+type naturalKey struct {
+	Network string
+	ChainID string
+	Kind    string
+	TaskID  string
+}
+
+func keyOf(rc structures.RunConfig) naturalKey {
+	return naturalKey{Network: rc.Network, ChainID: rc.ChainID, Kind: rc.Kind, TaskID: rc.TaskID}
+}
+
+// PlanSchedules compares schedule definitions with the stored schedules, matching them by network, chain_id, kind and task_id.
+// Missing schedules are created, changed ones updated and the managed ones that are not defined anymore - disabled.
+// Schedules created through API or by other runners are never disabled.
+func (c *Core) PlanSchedules(ctx context.Context, defs []ScheduleDefinition) (plan ReconcilePlan, err error) {
+	current, err := c.coreStore.GetConfigs(ctx)
+	if err != nil {
+		return plan, fmt.Errorf("error getting configs: %w", err)
+	}
+
+	currentByKey := make(map[naturalKey][]structures.RunConfig, len(current))
+	archivedByKey := make(map[naturalKey][]structures.RunConfig)
+	for _, rc := range current {
+		if rc.Status == structures.StateArchived {
+			archivedByKey[keyOf(rc)] = append(archivedByKey[keyOf(rc)], rc)
+			continue
+		}
+		currentByKey[keyOf(rc)] = append(currentByKey[keyOf(rc)], rc)
+	}
+
+	seen := make(map[naturalKey]struct{})
+	for _, def := range defs {
+		desired, err := runConfigFromDefinition(def)
+		if err != nil {
+			return plan, err
+		}
+
+		change := ReconcileChange{
+			Network:       desired.Network,
+			ChainID:       desired.ChainID,
+			Kind:          desired.Kind,
+			TaskID:        desired.TaskID,
+			desired:       desired,
+			manageEnabled: def.Enabled != nil,
+		}
+
+		seen[keyOf(desired)] = struct{}{}
+		cur, ok := matchSchedule(currentByKey[keyOf(desired)], desired.Version)
+		if !ok {
+			// archived schedule of the same version blocks creating new one
+			if arch, ok := matchSchedule(archivedByKey[keyOf(desired)], desired.Version); ok && arch.Version == desired.Version {
+				change.Action = ReconcileSkip
+				change.ID = arch.ID
+				change.Diff = []string{"status: archived"}
+				plan.Changes = append(plan.Changes, change)
+				continue
+			}
+			change.Action = ReconcileCreate
+			plan.Changes = append(plan.Changes, change)
+			continue
+		}
+
+		if diff := diffSchedule(cur, desired, change.manageEnabled); len(diff) > 0 {
+			change.Action = ReconcileUpdate
+			change.ID = cur.ID
+			change.Diff = diff
+			change.desired.ID = cur.ID
+			change.desired.Revision = cur.Revision
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+
+	for _, rc := range current {
+		// schedules sharing natural key with defined one differ only by version, they are not removed from config
+		if _, ok := seen[keyOf(rc)]; ok || !rc.Enabled || rc.Status == structures.StateArchived || rc.Labels[ManagedLabel] != ManagedLabelValue {
+			continue
+		}
+		plan.Changes = append(plan.Changes, ReconcileChange{
+			Action:  ReconcileDisable,
+			ID:      rc.ID,
+			Network: rc.Network,
+			ChainID: rc.ChainID,
+			Kind:    rc.Kind,
+			TaskID:  rc.TaskID,
+			Diff:    []string{"enabled: true -> false"},
+		})
+	}
+
+	return plan, nil
+}
+
+// ApplySchedules executes previously created plan. Changes already made by other instance (reconciling at the same time) are skipped.
+func (c *Core) ApplySchedules(ctx context.Context, plan ReconcilePlan) error {
+	for _, ch := range plan.Changes {
+		c.logger.Info("[Core] Reconciling schedule",
+			zap.String("action", string(ch.Action)),
+			zap.String("kind", ch.Kind),
+			zap.String("network", ch.Network),
+			zap.String("chain", ch.ChainID),
+			zap.String("task_id", ch.TaskID),
+			zap.Strings("diff", ch.Diff),
+		)
+
+		switch ch.Action {
+		case ReconcileCreate:
+			rc := ch.desired
+			rc.RunID = c.ID
+			rc.Status = structures.StateAdded
+			if err := c.coreStore.AddConfig(ctx, rc); err != nil {
+				if errors.Is(err, params.ErrAlreadyRegistred) {
+					c.logger.Info("[Core] Schedule already created", zap.String("task_id", ch.TaskID))
+					continue
+				}
+				return fmt.Errorf("error adding config: %w", err)
+			}
+		case ReconcileUpdate:
+			cur, err := c.getConfig(ctx, ch.ID)
+			if err != nil {
+				return err
+			}
+			if cur.Revision != ch.desired.Revision {
+				c.logger.Info("[Core] Schedule was modified after planning, skipping", zap.String("id", ch.ID.String()))
+				continue
+			}
+
+			if diff := diffSchedule(cur, ch.desired, false); len(diff) > 0 {
+				if err := c.UpdateSchedule(ctx, ch.desired); err != nil {
+					if errors.Is(err, params.ErrRevisionMismatch) {
+						c.logger.Info("[Core] Schedule was modified after planning, skipping", zap.String("id", ch.ID.String()))
+						continue
+					}
+					return err
+				}
+			}
+
+			if ch.manageEnabled && cur.Enabled != ch.desired.Enabled {
+				if err := c.setEnabled(ctx, ch.ID, ch.desired.Enabled); err != nil {
+					return err
+				}
+			}
+		case ReconcileDisable:
+			if err := c.setEnabled(ctx, ch.ID, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Core) setEnabled(ctx context.Context, sID uuid.UUID, enabled bool) error {
+	if enabled {
+		if err := c.EnableSchedule(ctx, sID); err != nil && !errors.Is(err, ErrLeaseHeld) {
+			return fmt.Errorf("error enabling schedule: %w", err)
+		}
+		return nil
+	}
+
+	if err := c.DisableSchedule(ctx, sID); err != nil && !errors.Is(err, ErrAlreadyDisabled) {
+		return fmt.Errorf("error disabling schedule: %w", err)
+	}
+	return nil
+}
+
+// runConfigFromDefinition validates the definition and converts it into schedule, marked as managed by the schedules config
+func runConfigFromDefinition(def ScheduleDefinition) (rc structures.RunConfig, err error) {
+	if def.Kind == "" || def.Network == "" || def.ChainID == "" || def.TaskID == "" {
+		return rc, fmt.Errorf("kind, network, chain_id and task_id are required (%s:%s %s %s)", def.Network, def.ChainID, def.Kind, def.TaskID)
+	}
+
+	labels := make(map[string]string, len(def.Labels)+1)
+	for k, v := range def.Labels {
+		labels[k] = v
+	}
+	labels[ManagedLabel] = ManagedLabelValue
+
+	rc = structures.RunConfig{
+		Network: def.Network,
+		ChainID: def.ChainID,
+		Kind:    def.Kind,
+		TaskID:  def.TaskID,
+		Version: def.Version,
+		Cron:    def.Cron,
+		Config:  def.Config,
+		Labels:  labels,
+
+		RetryPolicy: def.RetryPolicy,
+
+		Dependencies: def.Dependencies,
+		MissedRuns:   def.MissedRuns,
+	}
+
+	if rc.Version == "" {
+		rc.Version = DefaultVersion
+	}
+
+	if def.Interval != "" {
+		if rc.Duration, err = time.ParseDuration(def.Interval); err != nil {
+			return rc, fmt.Errorf("error parsing interval of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+		}
+	}
+
+	if _, err := process.NewSchedule(rc); err != nil {
+		return rc, fmt.Errorf("error creating schedule of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+	}
+
+	if def.Timeout != "" {
+		if rc.Timeout, err = time.ParseDuration(def.Timeout); err != nil {
+			return rc, fmt.Errorf("error parsing timeout of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+		}
+	}
+
+	if err := validateDependencies(rc.Dependencies); err != nil {
+		return rc, fmt.Errorf("error in dependencies of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+	}
+
+	if rc.StartOffset, err = parseOptionalDuration(def.StartOffset); err != nil {
+		return rc, fmt.Errorf("error parsing start_offset of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+	}
+	if rc.Jitter, err = parseOptionalDuration(def.Jitter); err != nil {
+		return rc, fmt.Errorf("error parsing jitter of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+	}
+
+	if def.StartAt != nil {
+		rc.StartAt = *def.StartAt
+	}
+	if def.EndAt != nil {
+		rc.EndAt = *def.EndAt
+	}
+	if err := rc.ValidateWindow(); err != nil {
+		return rc, fmt.Errorf("error in schedule window of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+	}
+
+	if rc.RetryPolicy != nil {
+		if err := rc.RetryPolicy.Validate(); err != nil {
+			return rc, fmt.Errorf("error in retry policy of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+		}
+	}
+
+	if err := rc.MissedRuns.Validate(); err != nil {
+		return rc, fmt.Errorf("error in missed_runs of %s (%s:%s): %w", def.TaskID, def.Network, def.ChainID, err)
+	}
+
+	if def.Enabled != nil {
+		rc.Enabled = *def.Enabled
+	}
+
+	return rc, nil
+}
+
+// matchSchedule picks the stored schedule of the same version, or the first one when none matches
+func matchSchedule(rcs []structures.RunConfig, version string) (rc structures.RunConfig, ok bool) {
+	if len(rcs) == 0 {
+		return rc, false
+	}
+	for _, r := range rcs {
+		if r.Version == version {
+			return r, true
+		}
+	}
+	return rcs[0], true
+}
+
+func diffSchedule(cur, desired structures.RunConfig, manageEnabled bool) (diff []string) {
+	if cur.Duration != desired.Duration {
+		diff = append(diff, fmt.Sprintf("duration: %s -> %s", cur.Duration, desired.Duration))
+	}
+	if cur.Cron != desired.Cron {
+		diff = append(diff, fmt.Sprintf("cron: %q -> %q", cur.Cron, desired.Cron))
+	}
+	if cur.Version != desired.Version {
+		diff = append(diff, fmt.Sprintf("version: %s -> %s", cur.Version, desired.Version))
+	}
+	if cur.Timeout != desired.Timeout {
+		diff = append(diff, fmt.Sprintf("timeout: %s -> %s", cur.Timeout, desired.Timeout))
+	}
+	if cur.StartOffset != desired.StartOffset {
+		diff = append(diff, fmt.Sprintf("start_offset: %s -> %s", cur.StartOffset, desired.StartOffset))
+	}
+	if cur.Jitter != desired.Jitter {
+		diff = append(diff, fmt.Sprintf("jitter: %s -> %s", cur.Jitter, desired.Jitter))
+	}
+	if !cur.StartAt.Equal(desired.StartAt) {
+		diff = append(diff, fmt.Sprintf("start_at: %s -> %s", formatTime(cur.StartAt), formatTime(desired.StartAt)))
+	}
+	if !cur.EndAt.Equal(desired.EndAt) {
+		diff = append(diff, fmt.Sprintf("end_at: %s -> %s", formatTime(cur.EndAt), formatTime(desired.EndAt)))
+	}
+	if !sameConfig(cur.Config, desired.Config) {
+		diff = append(diff, fmt.Sprintf("config: %v -> %v", cur.Config, desired.Config))
+	}
+	if !sameLabels(cur.Labels, desired.Labels) {
+		diff = append(diff, fmt.Sprintf("labels: %v -> %v", cur.Labels, desired.Labels))
+	}
+	if !sameRetryPolicy(cur.RetryPolicy, desired.RetryPolicy) {
+		diff = append(diff, fmt.Sprintf("retry_policy: %s -> %s", formatRetryPolicy(cur.RetryPolicy), formatRetryPolicy(desired.RetryPolicy)))
+	}
+	if !sameDependencies(cur.Dependencies, desired.Dependencies) {
+		diff = append(diff, fmt.Sprintf("dependencies: %+v -> %+v", cur.Dependencies, desired.Dependencies))
+	}
+	if cur.MissedRuns != desired.MissedRuns {
+		diff = append(diff, fmt.Sprintf("missed_runs: %q -> %q", cur.MissedRuns, desired.MissedRuns))
+	}
+	if manageEnabled && cur.Enabled != desired.Enabled {
+		diff = append(diff, fmt.Sprintf("enabled: %t -> %t", cur.Enabled, desired.Enabled))
+	}
+	return diff
+}
+
+func sameConfig(a, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func sameLabels(a, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func sameRetryPolicy(a, b *structures.RetryPolicy) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatRetryPolicy(rp *structures.RetryPolicy) string {
+	if rp == nil {
+		return "default"
+	}
+	b, _ := json.Marshal(rp)
+	return string(b)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "none"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func sameDependencies(a, b []structures.Dependency) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/figment-networks/indexer-scheduler/structures"
+)
+
+var ErrEmptySelector = errors.New("selector cannot be empty")
+
+type selectorOperator string
+
+const (
+	selectorEquals    selectorOperator = "="
+	selectorNotEquals selectorOperator = "!="
+	selectorExists    selectorOperator = "exists"
+	selectorNotExists selectorOperator = "!exists"
+)
+
+type requirement struct {
+	key      string
+	operator selectorOperator
+	value    string
+}
+
+// Selector is a set of requirements, that all have to be met by the schedule
+type Selector []requirement
+
+// ParseSelector parses comma separated requirements, like `network=cosmos,env!=staging,critical,!deprecated`.
+// Keys `network`, `chain_id`, `kind`, `task_id`, `version` and `status` are matched against schedule fields, other keys against labels.
+func ParseSelector(s string) (sel Selector, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ErrEmptySelector
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		var req requirement
+		switch {
+		case part == "":
+			return nil, fmt.Errorf("empty requirement in selector: %s", s)
+		case strings.Contains(part, "!="):
+			kv := strings.SplitN(part, "!=", 2)
+			req = requirement{key: strings.TrimSpace(kv[0]), operator: selectorNotEquals, value: strings.TrimSpace(kv[1])}
+		case strings.Contains(part, "="):
+			kv := strings.SplitN(part, "=", 2)
+			req = requirement{key: strings.TrimSpace(kv[0]), operator: selectorEquals, value: strings.TrimSpace(kv[1])}
+		case strings.HasPrefix(part, "!"):
+			req = requirement{key: strings.TrimSpace(part[1:]), operator: selectorNotExists}
+		default:
+			req = requirement{key: part, operator: selectorExists}
+		}
+
+		if req.key == "" {
+			return nil, fmt.Errorf("empty key in selector requirement: %s", part)
+		}
+		sel = append(sel, req)
+	}
+
+	return sel, nil
+}
+
+// Matches checks if schedule meets all the requirements
+func (sel Selector) Matches(rc structures.RunConfig) bool {
+	for _, req := range sel {
+		value, ok := fieldValue(rc, req.key)
+		switch req.operator {
+		case selectorEquals:
+			if !ok || value != req.value {
+				return false
+			}
+		case selectorNotEquals:
+			if ok && value == req.value {
+				return false
+			}
+		case selectorExists:
+			if !ok {
+				return false
+			}
+		case selectorNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func fieldValue(rc structures.RunConfig, key string) (string, bool) {
+	switch key {
+	case "network":
+		return rc.Network, true
+	case "chain_id":
+		return rc.ChainID, true
+	case "kind":
+		return rc.Kind, true
+	case "task_id":
+		return rc.TaskID, true
+	case "version":
+		return rc.Version, true
+	case "status":
+		return string(rc.Status), true
+	}
+
+	v, ok := rc.Labels[key]
+	return v, ok
+}
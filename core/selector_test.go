@@ -0,0 +1,111 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/figment-networks/indexer-scheduler/structures"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Selector
+		wantErr error
+	}{
+		{name: "equals", s: "network=cosmos", want: Selector{{key: "network", operator: selectorEquals, value: "cosmos"}}},
+		{name: "not equals", s: "env!=staging", want: Selector{{key: "env", operator: selectorNotEquals, value: "staging"}}},
+		{name: "exists", s: "critical", want: Selector{{key: "critical", operator: selectorExists}}},
+		{name: "not exists", s: "!deprecated", want: Selector{{key: "deprecated", operator: selectorNotExists}}},
+		{name: "empty value", s: "env=", want: Selector{{key: "env", operator: selectorEquals}}},
+		{name: "value with equals sign", s: "expr=a=b", want: Selector{{key: "expr", operator: selectorEquals, value: "a=b"}}},
+		{
+			name: "whitespace",
+			s:    "  network = cosmos ,  env != staging , critical, ! deprecated ",
+			want: Selector{
+				{key: "network", operator: selectorEquals, value: "cosmos"},
+				{key: "env", operator: selectorNotEquals, value: "staging"},
+				{key: "critical", operator: selectorExists},
+				{key: "deprecated", operator: selectorNotExists},
+			},
+		},
+		{name: "empty", s: "", wantErr: ErrEmptySelector},
+		{name: "blank", s: "  ", wantErr: ErrEmptySelector},
+		{name: "empty requirement", s: "network=cosmos,,env=prod"},
+		{name: "trailing comma", s: "network=cosmos,"},
+		{name: "missing key", s: "=cosmos"},
+		{name: "missing key of not equals", s: "!=cosmos"},
+		{name: "missing key of not exists", s: "!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSelector(tt.s)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("expected error parsing %q", tt.s)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSelector(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	rc := structures.RunConfig{
+		Network: "cosmos",
+		ChainID: "cosmoshub-4",
+		Kind:    "lastdata",
+		TaskID:  "blocks",
+		Version: "0.0.1",
+		Status:  structures.StateRunning,
+		Labels:  map[string]string{"env": "prod", "critical": ""},
+	}
+
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "network=cosmos", want: true},
+		{s: "network=terra"},
+		{s: "chain_id=cosmoshub-4,kind=lastdata,task_id=blocks,version=0.0.1", want: true},
+		{s: "status=" + string(structures.StateRunning), want: true},
+		{s: "env=prod", want: true},
+		{s: "env=staging"},
+		{s: "team=core"},
+		{s: "env!=staging", want: true},
+		{s: "env!=prod"},
+		{s: "team!=core", want: true},
+		{s: "critical", want: true},
+		{s: "team"},
+		{s: "network", want: true},
+		{s: "!team", want: true},
+		{s: "!critical"},
+		{s: "critical=", want: true},
+		{s: "network=cosmos,env=prod,!deprecated", want: true},
+		{s: "network=cosmos,env=staging"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			sel, err := ParseSelector(tt.s)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := sel.Matches(rc); got != tt.want {
+				t.Errorf("Matches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package destination
 
 import (
 	"context"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/figment-networks/indexer-scheduler/conn/tray"
 	"github.com/figment-networks/indexer-scheduler/destination/manager"
@@ -16,12 +19,21 @@ type Destination interface {
 type Container struct {
 	logger       *zap.Logger
 	destinations map[string]Destination
+
+	loaded     map[string]loadedTarget
+	loadedLock sync.Mutex
+}
+
+type loadedTarget struct {
+	config structures.TargetConfig
+	cancel context.CancelFunc
 }
 
 func NewContainer(logger *zap.Logger) (c *Container) {
 	return &Container{
 		logger:       logger,
 		destinations: make(map[string]Destination),
+		loaded:       make(map[string]loadedTarget),
 	}
 }
 
@@ -36,3 +48,57 @@ func (c *Container) Add(ctx context.Context, t structures.TargetConfig, ct *tray
 
 	return nil
 }
+
+// Sync converges loaded destinations with the given list. Destinations that are no longer listed
+// are removed from the scheme and their connections are closed, unless other listed destination uses them.
+// Workers discovered by manager are reached through the manager address, so removing the manager
+// removes the targets it added (static targets of the same address stay) and closes their connection.
+// Connections to custom addresses, not coming from config, are left untouched.
+func (c *Container) Sync(ctx context.Context, tcs []structures.TargetConfig, ct *tray.ConnTray, ta manager.TargetAdder) error {
+	c.loadedLock.Lock()
+	defer c.loadedLock.Unlock()
+
+	desired := make(map[string]structures.TargetConfig, len(tcs))
+	for _, t := range tcs {
+		desired[targetKey(t)] = t
+	}
+
+	removed := make(map[tray.PAKey]struct{})
+	for k, lt := range c.loaded {
+		if t, ok := desired[k]; ok && reflect.DeepEqual(t.AdditionalConfig, lt.config.AdditionalConfig) {
+			continue
+		}
+		c.logger.Info("[Container] Removing destination", zap.String("type", lt.config.Type), zap.String("network", lt.config.Network), zap.String("chain_id", lt.config.ChainID), zap.String("address", lt.config.Address))
+		lt.cancel()
+		if lt.config.Type != "manager" {
+			ta.Remove(lt.config.Target)
+		}
+		removed[tray.PAKey{Protocol: lt.config.ConnType, Address: lt.config.Address}] = struct{}{}
+		delete(c.loaded, k)
+	}
+
+	for k, t := range desired {
+		if _, ok := c.loaded[k]; ok {
+			continue
+		}
+		tCtx, cancel := context.WithCancel(ctx)
+		if err := c.Add(tCtx, t, ct, ta); err != nil {
+			cancel()
+			return err
+		}
+		c.loaded[k] = loadedTarget{config: t, cancel: cancel}
+	}
+
+	for _, lt := range c.loaded {
+		delete(removed, tray.PAKey{Protocol: lt.config.ConnType, Address: lt.config.Address})
+	}
+	for pa := range removed {
+		ct.Close(pa.Protocol, pa.Address)
+	}
+
+	return nil
+}
+
+func targetKey(t structures.TargetConfig) string {
+	return strings.Join([]string{t.Type, t.Network, t.ChainID, t.Version, t.ConnType, t.Address}, "|")
+}
@@ -61,6 +61,8 @@ type Manager struct {
 	logger *zap.Logger
 	ta     TargetAdder
 	nodes  map[string]WorkerInfoStatic // NodeSelfID
+	// held are the targets added by this manager, each of them holds a single reference in TargetAdder
+	held map[structures.NVCKey]structures.Target
 }
 
 func NewManager(logger *zap.Logger, ta TargetAdder) *Manager {
@@ -68,6 +70,7 @@ func NewManager(logger *zap.Logger, ta TargetAdder) *Manager {
 		logger: logger,
 		ta:     ta,
 		nodes:  make(map[string]WorkerInfoStatic),
+		held:   make(map[structures.NVCKey]structures.Target),
 	}
 }
 
@@ -87,9 +90,14 @@ func (m *Manager) Load(ctx context.Context, t structures.Target, ct *tray.ConnTr
 	dec := json.NewDecoder(readr)
 
 	tckr := time.NewTicker(time.Second * 10)
+	defer tckr.Stop()
 	for {
 		select {
 		case <-ctx.Done():
+			// remove everything that was added by this manager
+			for _, at := range m.held {
+				m.remove(at)
+			}
 			return
 		case <-tckr.C:
 			rcpconn.Send(sID.String(), ch, 0, "get_workers", nil)
@@ -113,7 +121,7 @@ func (m *Manager) Load(ctx context.Context, t structures.Target, ct *tray.ConnTr
 					if !ok && w.State == StreamOnline {
 						m.nodes[w.NodeSelfID] = w
 						for _, ci := range w.ConnectionInfo {
-							m.ta.Add(structures.Target{
+							m.add(structures.Target{
 								Network:          network,
 								Version:          ci.Version,
 								ChainID:          w.ChainID,
@@ -127,7 +135,7 @@ func (m *Manager) Load(ctx context.Context, t structures.Target, ct *tray.ConnTr
 
 					if n.State == StreamOnline && w.State != StreamOnline {
 						for _, ci := range w.ConnectionInfo {
-							m.ta.Remove(structures.Target{
+							m.remove(structures.Target{
 								Network:  network,
 								Version:  ci.Version,
 								ChainID:  w.ChainID,
@@ -143,7 +151,7 @@ func (m *Manager) Load(ctx context.Context, t structures.Target, ct *tray.ConnTr
 				for k, n := range m.nodes {
 					if _, ok := sub.Workers[k]; !ok {
 						for _, ci := range n.ConnectionInfo {
-							m.ta.Remove(structures.Target{
+							m.remove(structures.Target{
 								Network:  network,
 								Version:  ci.Version,
 								ChainID:  n.ChainID,
@@ -152,10 +160,10 @@ func (m *Manager) Load(ctx context.Context, t structures.Target, ct *tray.ConnTr
 							})
 						}
 						delete(m.nodes, k)
-					} else {
+					} else if n.State == StreamOnline {
 						for _, ci := range n.ConnectionInfo {
 							if _, ok := m.ta.Get(structures.NVCKey{Network: network, Version: ci.Version, ChainID: n.ChainID}); !ok {
-								m.ta.Add(structures.Target{
+								m.add(structures.Target{
 									Network:          network,
 									Version:          ci.Version,
 									ChainID:          n.ChainID,
@@ -172,3 +180,24 @@ func (m *Manager) Load(ctx context.Context, t structures.Target, ct *tray.ConnTr
 		}
 	}
 }
+
+// add adds the target unless this manager holds it already
+func (m *Manager) add(t structures.Target) {
+	key := structures.NVCKey{Network: t.Network, Version: t.Version, ChainID: t.ChainID}
+	if _, ok := m.held[key]; ok {
+		return
+	}
+	m.held[key] = t
+	m.ta.Add(t)
+}
+
+// remove removes the target only if it was added by this manager, so static targets of the same address stay
+func (m *Manager) remove(t structures.Target) {
+	key := structures.NVCKey{Network: t.Network, Version: t.Version, ChainID: t.ChainID}
+	ht, ok := m.held[key]
+	if !ok {
+		return
+	}
+	delete(m.held, key)
+	m.ta.Remove(ht)
+}
@@ -10,9 +10,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// Targets are the destinations of single network/version/chain, served in round robin.
+// Every address is reference counted, as the same one may be added by config and discovered by manager.
 type Targets struct {
-	l sync.RWMutex
-	T []structures.Target
+	l    sync.RWMutex
+	T    []structures.Target
+	refs map[string]int
 
 	next  int
 	nextL sync.Mutex
@@ -22,7 +25,7 @@ type Targets struct {
 func (t *Targets) inc() int {
 	t.nextL.Lock()
 	defer t.nextL.Unlock()
-	if t.next == t.Len-1 {
+	if t.next >= t.Len-1 {
 		t.next = 0
 	} else {
 		t.next++
@@ -31,13 +34,17 @@ func (t *Targets) inc() int {
 	return t.next
 }
 
+// Add adds the target, returning false if its address was already there
 func (trgs *Targets) Add(t structures.Target) bool {
 	trgs.l.Lock()
 	defer trgs.l.Unlock()
-	for _, v := range trgs.T {
-		if v.Address == t.Address {
-			return false
-		}
+
+	if trgs.refs == nil {
+		trgs.refs = make(map[string]int)
+	}
+	trgs.refs[t.Address]++
+	if trgs.refs[t.Address] > 1 {
+		return false
 	}
 
 	trgs.T = append(trgs.T, t)
@@ -49,10 +56,17 @@ func (trgs *Targets) Count() uint64 {
 	return uint64(trgs.Len)
 }
 
+// Remove drops one reference of target address, the target is removed with the last one
 func (trgs *Targets) Remove(t structures.Target) {
 	trgs.l.Lock()
 	defer trgs.l.Unlock()
 
+	if trgs.refs[t.Address] > 1 {
+		trgs.refs[t.Address]--
+		return
+	}
+	delete(trgs.refs, t.Address)
+
 	var nT []structures.Target
 	for _, lt := range trgs.T {
 		if lt.Address != t.Address {
@@ -61,6 +75,13 @@ func (trgs *Targets) Remove(t structures.Target) {
 	}
 	trgs.T = nT
 	trgs.Len = len(trgs.T)
+
+	// next may point past the shrunk list
+	trgs.nextL.Lock()
+	if trgs.next >= trgs.Len {
+		trgs.next = 0
+	}
+	trgs.nextL.Unlock()
 }
 
 func (trgs *Targets) GetNext() (t structures.Target) {
@@ -90,14 +111,14 @@ func (s *Scheme) Add(t structures.Target) {
 	s.targetLock.Lock()
 	defer s.targetLock.Unlock()
 
-	i, ok := s.targets[structures.NVCKey{t.Network, t.Version, t.ChainID}]
+	i, ok := s.targets[structures.NVCKey{Network: t.Network, Version: t.Version, ChainID: t.ChainID}]
 	if !ok {
 		i = &Targets{}
 	}
 
 	if added := i.Add(t); added {
 		s.logger.Info("[Scheduler] Adding destination config", zap.String("connection_type", t.ConnType), zap.String("network", t.Network), zap.String("chain_id", t.ChainID))
-		s.targets[structures.NVCKey{t.Network, t.Version, t.ChainID}] = i
+		s.targets[structures.NVCKey{Network: t.Network, Version: t.Version, ChainID: t.ChainID}] = i
 	}
 }
 
@@ -116,7 +137,7 @@ func (s *Scheme) Remove(t structures.Target) {
 	s.targetLock.Lock()
 	defer s.targetLock.Unlock()
 
-	key := structures.NVCKey{t.Network, t.Version, t.ChainID}
+	key := structures.NVCKey{Network: t.Network, Version: t.Version, ChainID: t.ChainID}
 	targ, ok := s.targets[key]
 	if !ok {
 		return
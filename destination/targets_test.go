@@ -0,0 +1,50 @@
+package destination
+
+import (
+	"testing"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+func TestTargetsRemovePointedTarget(t *testing.T) {
+	trgs := &Targets{}
+	for _, addr := range []string{"a", "b", "c"} {
+		trgs.Add(structures.Target{Address: addr})
+	}
+
+	// move next to the last target
+	for trgs.GetNext().Address != "c" {
+	}
+
+	trgs.Remove(structures.Target{Address: "c"})
+
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		seen[trgs.GetNext().Address] = true
+	}
+	if len(seen) != 2 || !seen["a"] || !seen["b"] {
+		t.Errorf("expected to get remaining targets a and b, got %v", seen)
+	}
+}
+
+func TestSchemeAddressAddedTwice(t *testing.T) {
+	s := NewScheme(zap.NewNop(), auth.AuthCredentials{})
+	nv := structures.NVCKey{Network: "n", ChainID: "c", Version: "0.0.1"}
+	target := structures.Target{Network: nv.Network, ChainID: nv.ChainID, Version: nv.Version, Address: "a"}
+
+	// the same address configured statically and discovered by manager
+	s.Add(target)
+	s.Add(target)
+
+	s.Remove(target)
+	if _, ok := s.Get(nv); !ok {
+		t.Fatal("target removed while still referenced")
+	}
+
+	s.Remove(target)
+	if _, ok := s.Get(nv); ok {
+		t.Error("target still present after removing all the references")
+	}
+}
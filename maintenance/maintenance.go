@@ -0,0 +1,306 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/core"
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/maintenance/persistence"
+	"github.com/figment-networks/indexer-scheduler/maintenance/structures"
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/process/cron"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidWindow = errors.New("invalid maintenance window")
+
+// window is a maintenance window with parsed cron expression and selector
+type window struct {
+	structures.Window
+
+	expr *cron.Expression
+	sel  core.Selector
+}
+
+func newWindow(w structures.Window) (mw window, err error) {
+	mw = window{Window: w}
+
+	if w.Cron == "" {
+		if w.StartAt.IsZero() || w.EndAt.IsZero() {
+			return mw, fmt.Errorf("%w: one-off window requires start_at and end_at", ErrInvalidWindow)
+		}
+	} else {
+		if mw.expr, err = cron.Parse(w.Cron); err != nil {
+			return mw, fmt.Errorf("%w: %s", ErrInvalidWindow, err.Error())
+		}
+		if w.Duration <= 0 {
+			return mw, fmt.Errorf("%w: recurring window requires duration", ErrInvalidWindow)
+		}
+	}
+
+	if !w.StartAt.IsZero() && !w.EndAt.IsZero() && !w.EndAt.After(w.StartAt) {
+		return mw, fmt.Errorf("%w: end_at has to be after start_at", ErrInvalidWindow)
+	}
+
+	if w.Network == "" && w.ChainID == "" && w.Selector == "" && !w.All {
+		return mw, fmt.Errorf("%w: window requires network, chain_id, selector or explicit all", ErrInvalidWindow)
+	}
+
+	if w.Selector != "" {
+		if mw.sel, err = core.ParseSelector(w.Selector); err != nil {
+			return mw, fmt.Errorf("%w: %s", ErrInvalidWindow, err.Error())
+		}
+	}
+
+	return mw, nil
+}
+
+// Active checks if the window is in progress at t
+func (mw window) Active(t time.Time) bool {
+	if !mw.StartAt.IsZero() && t.Before(mw.StartAt) {
+		return false
+	}
+	if !mw.EndAt.IsZero() && !t.Before(mw.EndAt) {
+		return false
+	}
+	if mw.expr == nil {
+		return true
+	}
+
+	// the latest occurrence that started within the last duration
+	occurrence := mw.expr.Next(t.Add(-mw.Duration))
+	return !occurrence.IsZero() && !occurrence.After(t)
+}
+
+// Matches checks if the schedule is in scope of the window
+func (mw window) Matches(rc coreStructs.RunConfig) bool {
+	if mw.Network != "" && mw.Network != rc.Network {
+		return false
+	}
+	if mw.ChainID != "" && mw.ChainID != rc.ChainID {
+		return false
+	}
+	return mw.sel.Matches(rc)
+}
+
+// Manager keeps maintenance windows and skips the runs of schedules that are in active window
+type Manager struct {
+	store  *persistence.MaintenanceStorage
+	logger *zap.Logger
+	creds  auth.AuthCredentials
+
+	windows     []window
+	windowsLock sync.RWMutex
+}
+
+func NewManager(logger *zap.Logger, store *persistence.MaintenanceStorage, creds auth.AuthCredentials) *Manager {
+	return &Manager{
+		store:  store,
+		logger: logger,
+		creds:  creds,
+	}
+}
+
+// Load reads maintenance windows from the database
+func (m *Manager) Load(ctx context.Context) error {
+	ws, err := m.store.GetWindows(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting maintenance windows: %w", err)
+	}
+
+	windows := make([]window, 0, len(ws))
+	for _, w := range ws {
+		mw, err := newWindow(w)
+		if err != nil {
+			m.logger.Error("[Maintenance] Skipping invalid window", zap.String("id", w.ID.String()), zap.Error(err))
+			continue
+		}
+		windows = append(windows, mw)
+	}
+
+	m.windowsLock.Lock()
+	m.windows = windows
+	m.windowsLock.Unlock()
+	return nil
+}
+
+// Skip implements process.Gate
+func (m *Manager) Skip(rc coreStructs.RunConfig, t time.Time) (skip bool, reason string) {
+	m.windowsLock.RLock()
+	defer m.windowsLock.RUnlock()
+
+	for _, mw := range m.windows {
+		if mw.Active(t) && mw.Matches(rc) {
+			return true, fmt.Sprintf("maintenance window %s (%s)", mw.ID, mw.Description)
+		}
+	}
+	return false, ""
+}
+
+func (m *Manager) AddWindow(ctx context.Context, w structures.Window) (id uuid.UUID, err error) {
+	if _, err := newWindow(w); err != nil {
+		return id, err
+	}
+
+	if id, err = m.store.AddWindow(ctx, w); err != nil {
+		return id, fmt.Errorf("error adding maintenance window: %w", err)
+	}
+	return id, m.Load(ctx)
+}
+
+func (m *Manager) DeleteWindow(ctx context.Context, id uuid.UUID) error {
+	if err := m.store.DeleteWindow(ctx, id); err != nil {
+		return err
+	}
+	return m.Load(ctx)
+}
+
+// ListWindows lists all the windows, or only the ones active at t
+func (m *Manager) ListWindows(activeOnly bool, t time.Time) []structures.Window {
+	m.windowsLock.RLock()
+	defer m.windowsLock.RUnlock()
+
+	list := []structures.Window{}
+	for _, mw := range m.windows {
+		if !activeOnly || mw.Active(t) {
+			list = append(list, mw.Window)
+		}
+	}
+	return list
+}
+
+func (m *Manager) RegisterHandles(smux *http.ServeMux) {
+	smux.HandleFunc("/scheduler/maintenance/list", m.handlerListWindows)
+	smux.HandleFunc("/scheduler/maintenance/active", m.handlerActiveWindows)
+	smux.HandleFunc("/scheduler/maintenance/add", m.handlerAddWindow)
+	smux.HandleFunc("/scheduler/maintenance/delete/", m.handlerDeleteWindow)
+}
+
+func (m *Manager) handlerListWindows(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(m.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(m.ListWindows(false, time.Now()))
+}
+
+func (m *Manager) handlerActiveWindows(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(m.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(m.ListWindows(true, time.Now()))
+}
+
+type WindowAddRequest struct {
+	Description string `json:"description"`
+
+	Network  string `json:"network"`
+	ChainID  string `json:"chain_id"`
+	Selector string `json:"selector"`
+	All      bool   `json:"all"`
+
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+
+	Cron     string `json:"cron"`
+	Duration string `json:"duration"`
+}
+
+func (m *Manager) handlerAddWindow(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(m.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	war := WindowAddRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&war); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	mw := structures.Window{
+		Description: war.Description,
+		Network:     war.Network,
+		ChainID:     war.ChainID,
+		Selector:    war.Selector,
+		All:         war.All,
+		StartAt:     war.StartAt,
+		EndAt:       war.EndAt,
+		Cron:        war.Cron,
+	}
+
+	if war.Duration != "" {
+		var err error
+		if mw.Duration, err = time.ParseDuration(war.Duration); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+	}
+
+	id, err := m.AddWindow(r.Context(), mw)
+	if err != nil {
+		if errors.Is(err, ErrInvalidWindow) {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(string(`{"status":"ok","id":"` + id.String() + `"}`))
+}
+
+func (m *Manager) handlerDeleteWindow(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(m.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	wIDs := strings.Replace(r.URL.Path, "/scheduler/maintenance/delete/", "", -1)
+	wID, err := uuid.Parse(wIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if err := m.DeleteWindow(r.Context(), wID); err != nil {
+		if errors.Is(err, params.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(string(`{"status":"ok"}`))
+}
@@ -0,0 +1,36 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/figment-networks/indexer-scheduler/maintenance/structures"
+	"github.com/google/uuid"
+)
+
+type MDriver interface {
+	AddWindow(ctx context.Context, w structures.Window) (id uuid.UUID, err error)
+	GetWindows(ctx context.Context) (ws []structures.Window, err error)
+	DeleteWindow(ctx context.Context, id uuid.UUID) (err error)
+}
+
+type MaintenanceStorage struct {
+	Driver MDriver
+}
+
+func NewMaintenanceStorage(driver MDriver) *MaintenanceStorage {
+	return &MaintenanceStorage{
+		Driver: driver,
+	}
+}
+
+func (s *MaintenanceStorage) AddWindow(ctx context.Context, w structures.Window) (id uuid.UUID, err error) {
+	return s.Driver.AddWindow(ctx, w)
+}
+
+func (s *MaintenanceStorage) GetWindows(ctx context.Context) (ws []structures.Window, err error) {
+	return s.Driver.GetWindows(ctx)
+}
+
+func (s *MaintenanceStorage) DeleteWindow(ctx context.Context, id uuid.UUID) (err error) {
+	return s.Driver.DeleteWindow(ctx, id)
+}
@@ -0,0 +1,75 @@
+package postgresstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/maintenance/structures"
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/google/uuid"
+)
+
+type Driver struct {
+	db *sql.DB
+}
+
+func NewDriver(db *sql.DB) *Driver {
+	return &Driver{
+		db: db,
+	}
+}
+
+func (d *Driver) AddWindow(ctx context.Context, w structures.Window) (id uuid.UUID, err error) {
+	row := d.db.QueryRowContext(ctx, "INSERT INTO maintenance_window (description, network, chain_id, selector, all_schedules, start_at, end_at, cron, duration) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+		w.Description, w.Network, w.ChainID, w.Selector, w.All, nullTime(w.StartAt), nullTime(w.EndAt), w.Cron, w.Duration)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+func (d *Driver) GetWindows(ctx context.Context) (ws []structures.Window, err error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT id, description, network, chain_id, selector, all_schedules, start_at, end_at, cron, duration, created_at FROM maintenance_window ORDER BY created_at")
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		w := structures.Window{}
+		startAt := sql.NullTime{}
+		endAt := sql.NullTime{}
+		if err := rows.Scan(&w.ID, &w.Description, &w.Network, &w.ChainID, &w.Selector, &w.All, &startAt, &endAt, &w.Cron, &w.Duration, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		w.StartAt = startAt.Time
+		w.EndAt = endAt.Time
+		ws = append(ws, w)
+	}
+
+	return ws, rows.Err()
+}
+
+func (d *Driver) DeleteWindow(ctx context.Context, id uuid.UUID) error {
+	res, err := d.db.ExecContext(ctx, "DELETE FROM maintenance_window WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i == 0 {
+		return params.ErrNotFound
+	}
+
+	return nil
+}
+
+// nullTime stores zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,30 @@
+package structures
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Window is a period, during which matching schedules are not run.
+// One-off window lasts from StartAt to EndAt. Recurring window starts on every activation of Cron and lasts Duration,
+// in that case StartAt and EndAt optionally bound the period of recurrence.
+type Window struct {
+	ID          uuid.UUID `json:"id"`
+	Description string    `json:"description"`
+
+	// Scope of the window, empty values match every schedule.
+	// At least one of them has to be set, unless All explicitly scopes the window to every schedule
+	Network  string `json:"network"`
+	ChainID  string `json:"chain_id"`
+	Selector string `json:"selector"`
+	All      bool   `json:"all"`
+
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+
+	Cron     string        `json:"cron"`
+	Duration time.Duration `json:"duration"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
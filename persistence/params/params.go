@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrNotFound         = errors.New("record not found")
 	ErrAlreadyRegistred = errors.New("already registred")
+	ErrRevisionMismatch = errors.New("record was modified in the meantime, revision mismatch")
 )
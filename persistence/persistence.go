@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/figment-networks/indexer-scheduler/structures"
 	"github.com/google/uuid"
@@ -10,12 +11,22 @@ import (
 type CDriver interface {
 	AddConfig(ctx context.Context, rc structures.RunConfig) (err error)
 	GetConfigs(ctx context.Context) (rcs []structures.RunConfig, err error)
+	UpdateConfig(ctx context.Context, rc structures.RunConfig) (err error)
+	DeleteConfig(ctx context.Context, id uuid.UUID) (err error)
 
 	MarkRunning(ctx context.Context, runID, configID uuid.UUID) (err error)
-	MarkFinished(ctx context.Context, id uuid.UUID) (err error)
+	MarkFinished(ctx context.Context, runID, id uuid.UUID) (err error)
+	MarkFailed(ctx context.Context, runID, id uuid.UUID, lastErr error) (err error)
+	SaveRunState(ctx context.Context, runID, id uuid.UUID, state structures.RunState) (err error)
 
 	MarkStopped(ctx context.Context, id uuid.UUID) (err error)
+	MarkArchived(ctx context.Context, id uuid.UUID) (err error)
 	RemoveStatusAllEnabled(ctx context.Context) (err error)
+
+	AcquireLease(ctx context.Context, runID, configID uuid.UUID, ttl time.Duration) (acquired bool, err error)
+	RenewLeases(ctx context.Context, runID uuid.UUID, configIDs []uuid.UUID, ttl time.Duration) (renewed []uuid.UUID, err error)
+	ReleaseLease(ctx context.Context, runID, configID uuid.UUID) (err error)
+	MarkReleased(ctx context.Context, runID uuid.UUID, configIDs []uuid.UUID) (err error)
 }
 
 type CoreStorage struct {
@@ -30,6 +41,14 @@ func (cs *CoreStorage) GetConfigs(ctx context.Context) (rcs []structures.RunConf
 	return cs.Driver.GetConfigs(ctx)
 }
 
+func (cs *CoreStorage) UpdateConfig(ctx context.Context, rc structures.RunConfig) (err error) {
+	return cs.Driver.UpdateConfig(ctx, rc)
+}
+
+func (cs *CoreStorage) DeleteConfig(ctx context.Context, id uuid.UUID) (err error) {
+	return cs.Driver.DeleteConfig(ctx, id)
+}
+
 func (cs *CoreStorage) MarkRunning(ctx context.Context, runID, configID uuid.UUID) (err error) {
 	return cs.Driver.MarkRunning(ctx, runID, configID)
 }
@@ -38,10 +57,38 @@ func (cs *CoreStorage) MarkStopped(ctx context.Context, id uuid.UUID) (err error
 	return cs.Driver.MarkStopped(ctx, id)
 }
 
-func (cs *CoreStorage) MarkFinished(ctx context.Context, id uuid.UUID) (err error) {
-	return cs.Driver.MarkFinished(ctx, id)
+func (cs *CoreStorage) MarkArchived(ctx context.Context, id uuid.UUID) (err error) {
+	return cs.Driver.MarkArchived(ctx, id)
+}
+
+func (cs *CoreStorage) MarkFailed(ctx context.Context, runID, id uuid.UUID, lastErr error) (err error) {
+	return cs.Driver.MarkFailed(ctx, runID, id, lastErr)
+}
+
+func (cs *CoreStorage) MarkFinished(ctx context.Context, runID, id uuid.UUID) (err error) {
+	return cs.Driver.MarkFinished(ctx, runID, id)
 }
 
 func (cs *CoreStorage) RemoveStatusAllEnabled(ctx context.Context) (err error) {
 	return cs.Driver.RemoveStatusAllEnabled(ctx)
 }
+
+func (cs *CoreStorage) AcquireLease(ctx context.Context, runID, configID uuid.UUID, ttl time.Duration) (acquired bool, err error) {
+	return cs.Driver.AcquireLease(ctx, runID, configID, ttl)
+}
+
+func (cs *CoreStorage) RenewLeases(ctx context.Context, runID uuid.UUID, configIDs []uuid.UUID, ttl time.Duration) (renewed []uuid.UUID, err error) {
+	return cs.Driver.RenewLeases(ctx, runID, configIDs, ttl)
+}
+
+func (cs *CoreStorage) ReleaseLease(ctx context.Context, runID, configID uuid.UUID) (err error) {
+	return cs.Driver.ReleaseLease(ctx, runID, configID)
+}
+
+func (cs *CoreStorage) MarkReleased(ctx context.Context, runID uuid.UUID, configIDs []uuid.UUID) (err error) {
+	return cs.Driver.MarkReleased(ctx, runID, configIDs)
+}
+
+func (cs *CoreStorage) SaveRunState(ctx context.Context, runID, id uuid.UUID, state structures.RunState) (err error) {
+	return cs.Driver.SaveRunState(ctx, runID, id, state)
+}
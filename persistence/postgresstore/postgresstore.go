@@ -12,6 +12,7 @@ import (
 	"github.com/figment-networks/indexer-scheduler/structures"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Driver struct {
@@ -25,7 +26,7 @@ func NewDriver(db *sql.DB) *Driver {
 }
 
 func (d *Driver) GetConfigs(ctx context.Context) (rcs []structures.RunConfig, err error) {
-	rows, err := d.db.QueryContext(ctx, "SELECT id, run_id, network, chain_id, version, duration, kind, task_id, enabled, status, config FROM schedule")
+	rows, err := d.db.QueryContext(ctx, "SELECT id, run_id, network, chain_id, version, duration, timeout, start_at, end_at, start_offset, jitter, cron, kind, task_id, enabled, status, config, labels, retry_policy, dependencies, missed_runs, last_run, next_run, backoff_iteration, last_error, failed_at, revision, lease_expires FROM schedule")
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, params.ErrNotFound
@@ -39,22 +40,115 @@ func (d *Driver) GetConfigs(ctx context.Context) (rcs []structures.RunConfig, er
 		rc := structures.RunConfig{}
 
 		configJSON := []byte{}
-		if err := rows.Scan(&rc.ID, &rc.RunID, &rc.Network, &rc.ChainID, &rc.Version, &rc.Duration, &rc.Kind, &rc.TaskID, &rc.Enabled, &rc.Status, &configJSON); err != nil {
+		labelsJSON := []byte{}
+		retryPolicyJSON := []byte{}
+		dependenciesJSON := []byte{}
+		startAt := sql.NullTime{}
+		endAt := sql.NullTime{}
+		failedAt := sql.NullTime{}
+		lastRun := sql.NullTime{}
+		nextRun := sql.NullTime{}
+		leaseExpires := sql.NullTime{}
+		if err := rows.Scan(&rc.ID, &rc.RunID, &rc.Network, &rc.ChainID, &rc.Version, &rc.Duration, &rc.Timeout, &startAt, &endAt, &rc.StartOffset, &rc.Jitter, &rc.Cron, &rc.Kind, &rc.TaskID, &rc.Enabled, &rc.Status, &configJSON, &labelsJSON, &retryPolicyJSON, &dependenciesJSON, &rc.MissedRuns, &lastRun, &nextRun, &rc.BackoffIteration, &rc.LastError, &failedAt, &rc.Revision, &leaseExpires); err != nil {
 			return nil, err
 		}
+		rc.StartAt = startAt.Time
+		rc.LastRun = lastRun.Time
+		rc.NextRun = nextRun.Time
+		rc.EndAt = endAt.Time
+		rc.FailedAt = failedAt.Time
+		rc.LeaseExpires = leaseExpires.Time
 
 		if err := json.Unmarshal(configJSON, &rc.Config); err != nil {
 			return nil, err
 		}
 
+		if err := json.Unmarshal(labelsJSON, &rc.Labels); err != nil {
+			return nil, err
+		}
+
+		if len(retryPolicyJSON) > 0 {
+			if err := json.Unmarshal(retryPolicyJSON, &rc.RetryPolicy); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := json.Unmarshal(dependenciesJSON, &rc.Dependencies); err != nil {
+			return nil, err
+		}
+
 		rcs = append(rcs, rc)
 	}
 
 	return rcs, nil
 }
 
+// UpdateConfig updates schedule parameters, only if the stored revision is still the same as in rc
+func (d *Driver) UpdateConfig(ctx context.Context, rc structures.RunConfig) error {
+	configJSON, err := json.Marshal(rc.Config)
+	if err != nil {
+		return err
+	}
+
+	labelsJSON, err := labelsToJSON(rc.Labels)
+	if err != nil {
+		return err
+	}
+
+	retryPolicyJSON, err := retryPolicyToJSON(rc.RetryPolicy)
+	if err != nil {
+		return err
+	}
+
+	dependenciesJSON, err := dependenciesToJSON(rc.Dependencies)
+	if err != nil {
+		return err
+	}
+
+	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET duration = $1, cron = $2, version = $3, config = $4, labels = $5, retry_policy = $6, timeout = $7, start_at = $8, end_at = $9, start_offset = $10, jitter = $11, dependencies = $12, missed_runs = $13, next_run = NULL, backoff_iteration = 0, revision = revision + 1 WHERE id = $14 AND revision = $15", rc.Duration, rc.Cron, rc.Version, configJSON, labelsJSON, retryPolicyJSON, rc.Timeout, nullTime(rc.StartAt), nullTime(rc.EndAt), rc.StartOffset, rc.Jitter, dependenciesJSON, rc.MissedRuns, rc.ID, rc.Revision)
+	if err != nil {
+		return err
+	}
+
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if i == 0 {
+		var revision uint64
+		row := d.db.QueryRowContext(ctx, "SELECT revision FROM schedule WHERE id = $1", rc.ID)
+		if err := row.Scan(&revision); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return params.ErrNotFound
+			}
+			return err
+		}
+		return params.ErrRevisionMismatch
+	}
+
+	return nil
+}
+
+func (d *Driver) DeleteConfig(ctx context.Context, id uuid.UUID) error {
+	res, err := d.db.ExecContext(ctx, "DELETE FROM schedule WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i == 0 {
+		return params.ErrNotFound
+	}
+
+	return nil
+}
+
 func (d *Driver) MarkRunning(ctx context.Context, runID, configID uuid.UUID) error {
-	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET run_id = $1, enabled = true, status = $2 WHERE id = $3 ", runID, structures.StateRunning, configID)
+	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET run_id = $1, enabled = true, status = $2, last_error = '', failed_at = NULL WHERE id = $3 ", runID, structures.StateRunning, configID)
 	if err != nil {
 		return err
 	}
@@ -70,8 +164,14 @@ func (d *Driver) MarkRunning(ctx context.Context, runID, configID uuid.UUID) err
 	return nil
 }
 
+// RemoveStatusAllEnabled resets the state of enabled schedules that are not held by any live instance
 func (d *Driver) RemoveStatusAllEnabled(ctx context.Context) error {
-	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET status = $1 WHERE enabled = true", structures.StateAdded)
+	_, err := d.db.ExecContext(ctx, "UPDATE schedule SET status = $1 WHERE enabled = true AND (lease_expires IS NULL OR lease_expires < NOW())", structures.StateAdded)
+	return err
+}
+
+func (d *Driver) MarkStopped(ctx context.Context, id uuid.UUID) error {
+	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET enabled = false, status = $2, next_run = NULL, lease_expires = NULL WHERE id = $1 ", id, structures.StateStopped)
 	if err != nil {
 		return err
 	}
@@ -87,8 +187,26 @@ func (d *Driver) RemoveStatusAllEnabled(ctx context.Context) error {
 	return nil
 }
 
-func (d *Driver) MarkStopped(ctx context.Context, id uuid.UUID) error {
-	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET enabled = false, status = $2 WHERE id = $1 ", id, structures.StateStopped)
+func (d *Driver) MarkArchived(ctx context.Context, id uuid.UUID) error {
+	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET enabled = false, status = $2, lease_expires = NULL WHERE id = $1", id, structures.StateArchived)
+	if err != nil {
+		return err
+	}
+
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i == 0 {
+		return params.ErrNotFound
+	}
+
+	return nil
+}
+
+// MarkFinished disables schedule that has nothing more to do, as long as it's still owned by runID
+func (d *Driver) MarkFinished(ctx context.Context, runID, id uuid.UUID) error {
+	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET enabled = false, status = $2, lease_expires = NULL WHERE id = $1 AND run_id = $3", id, structures.StateFinished, runID)
 	if err != nil {
 		return err
 	}
@@ -104,8 +222,15 @@ func (d *Driver) MarkStopped(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (d *Driver) MarkFinished(ctx context.Context, id uuid.UUID) error {
-	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET enabled = false, status = $2 WHERE id = $1", id, structures.StateFinished)
+// MarkFailed disables schedule that cannot continue, storing the error. It has to be enabled explicitly to run again.
+// Schedule taken over by other instance is not changed.
+func (d *Driver) MarkFailed(ctx context.Context, runID, id uuid.UUID, lastErr error) error {
+	var msg string
+	if lastErr != nil {
+		msg = lastErr.Error()
+	}
+
+	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET enabled = false, status = $2, last_error = $3, failed_at = NOW(), next_run = NULL, backoff_iteration = 0, lease_expires = NULL WHERE id = $1 AND run_id = $4", id, structures.StateFailed, msg, runID)
 	if err != nil {
 		return err
 	}
@@ -121,6 +246,88 @@ func (d *Driver) MarkFinished(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// SaveRunState stores the progress of schedule, unless it was taken over by other instance
+func (d *Driver) SaveRunState(ctx context.Context, runID, id uuid.UUID, state structures.RunState) error {
+	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET last_run = $2, next_run = $3, backoff_iteration = $4 WHERE id = $1 AND run_id = $5", id, nullTime(state.LastRun), nullTime(state.NextRun), state.BackoffIteration, runID)
+	if err != nil {
+		return err
+	}
+
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i == 0 {
+		return params.ErrNotFound
+	}
+
+	return nil
+}
+
+// AcquireLease takes the ownership of schedule, if it's not held by any other live instance
+func (d *Driver) AcquireLease(ctx context.Context, runID, configID uuid.UUID, ttl time.Duration) (acquired bool, err error) {
+	res, err := d.db.ExecContext(ctx, "UPDATE schedule SET run_id = $1, lease_expires = NOW() + $2 * INTERVAL '1 millisecond' WHERE id = $3 AND (run_id = $1 OR lease_expires IS NULL OR lease_expires < NOW())", runID, ttl.Milliseconds(), configID)
+	if err != nil {
+		return false, err
+	}
+
+	i, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return i > 0, nil
+}
+
+// RenewLeases extends leases of given schedules that are still owned by runID, returning ones that were renewed
+func (d *Driver) RenewLeases(ctx context.Context, runID uuid.UUID, configIDs []uuid.UUID, ttl time.Duration) (renewed []uuid.UUID, err error) {
+	if len(configIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(configIDs))
+	for i, id := range configIDs {
+		ids[i] = id.String()
+	}
+
+	rows, err := d.db.QueryContext(ctx, "UPDATE schedule SET lease_expires = NOW() + $2 * INTERVAL '1 millisecond' WHERE run_id = $1 AND enabled = true AND id = ANY($3::uuid[]) RETURNING id", runID, ttl.Milliseconds(), pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		renewed = append(renewed, id)
+	}
+
+	return renewed, rows.Err()
+}
+
+// ReleaseLease gives up the ownership of schedule, so it may be taken by other instance straight away
+func (d *Driver) ReleaseLease(ctx context.Context, runID, configID uuid.UUID) error {
+	_, err := d.db.ExecContext(ctx, "UPDATE schedule SET lease_expires = NULL WHERE id = $1 AND run_id = $2", configID, runID)
+	return err
+}
+
+// MarkReleased gives up the ownership of still running schedules, leaving them enabled for other instances to take over
+func (d *Driver) MarkReleased(ctx context.Context, runID uuid.UUID, configIDs []uuid.UUID) error {
+	if len(configIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(configIDs))
+	for i, id := range configIDs {
+		ids[i] = id.String()
+	}
+
+	_, err := d.db.ExecContext(ctx, "UPDATE schedule SET status = $1, lease_expires = NULL WHERE run_id = $2 AND status = $3 AND id = ANY($4::uuid[])", structures.StateAdded, runID, structures.StateRunning, pq.Array(ids))
+	return err
+}
+
 func (d *Driver) AddConfig(ctx context.Context, rc structures.RunConfig) (err error) {
 
 	var rID uuid.UUID
@@ -137,7 +344,22 @@ func (d *Driver) AddConfig(ctx context.Context, rc structures.RunConfig) (err er
 			return err
 		}
 
-		res, err := d.db.ExecContext(ctx, "INSERT INTO schedule (run_id, network, version, chain_id, duration, kind, task_id, enabled, status, config) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)", rc.RunID, rc.Network, rc.Version, rc.ChainID, rc.Duration, rc.Kind, rc.TaskID, rc.Enabled, structures.StateAdded, configJSON)
+		labelsJSON, err := labelsToJSON(rc.Labels)
+		if err != nil {
+			return err
+		}
+
+		retryPolicyJSON, err := retryPolicyToJSON(rc.RetryPolicy)
+		if err != nil {
+			return err
+		}
+
+		dependenciesJSON, err := dependenciesToJSON(rc.Dependencies)
+		if err != nil {
+			return err
+		}
+
+		res, err := d.db.ExecContext(ctx, "INSERT INTO schedule (run_id, network, version, chain_id, duration, cron, kind, task_id, enabled, status, config, labels, retry_policy, timeout, start_at, end_at, start_offset, jitter, dependencies, missed_runs) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)", rc.RunID, rc.Network, rc.Version, rc.ChainID, rc.Duration, rc.Cron, rc.Kind, rc.TaskID, rc.Enabled, structures.StateAdded, configJSON, labelsJSON, retryPolicyJSON, rc.Timeout, nullTime(rc.StartAt), nullTime(rc.EndAt), rc.StartOffset, rc.Jitter, dependenciesJSON, rc.MissedRuns)
 		if err != nil {
 			return err
 		}
@@ -172,3 +394,32 @@ func (d *Driver) AddConfig(ctx context.Context, rc structures.RunConfig) (err er
 
 	return params.ErrAlreadyRegistred
 }
+
+// labelsToJSON never returns `null`, as labels column is not nullable
+func labelsToJSON(labels map[string]string) ([]byte, error) {
+	if labels == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(labels)
+}
+
+// retryPolicyToJSON returns nil for empty policy, to store NULL
+func retryPolicyToJSON(rp *structures.RetryPolicy) ([]byte, error) {
+	if rp == nil {
+		return nil, nil
+	}
+	return json.Marshal(rp)
+}
+
+// nullTime stores zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// dependenciesToJSON never returns `null`, as dependencies column is not nullable
+func dependenciesToJSON(deps []structures.Dependency) ([]byte, error) {
+	if deps == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(deps)
+}
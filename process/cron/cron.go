@@ -0,0 +1,226 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEmptyExpression   = errors.New("empty cron expression")
+	ErrWrongFieldsNumber = errors.New("cron expression has to have 5 fields (minute hour day-of-month month day-of-week)")
+)
+
+// maxLookup is the upper bound of searching for next activation
+const maxLookup = 5 * 366 * 24 * time.Hour
+
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minuteBounds = bounds{0, 59, nil}
+	hourBounds   = bounds{0, 23, nil}
+	domBounds    = bounds{1, 31, nil}
+	monthBounds  = bounds{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowBounds = bounds{0, 7, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Expression is a parsed cron expression. All the times are evaluated in UTC.
+type Expression struct {
+	minute, hour, dom, month, dow uint64
+
+	domStar, dowStar bool
+
+	spec string
+}
+
+// Parse parses standard five field cron expression (minute hour day-of-month month day-of-week).
+// Fields support `*`, lists (`1,2`), ranges (`0-6`), steps (`*/5`, `0-30/10`) and month/day names.
+// Predefined descriptors like `@hourly` or `@daily` are also accepted.
+func Parse(spec string) (*Expression, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, ErrEmptyExpression
+	}
+
+	expanded := spec
+	if strings.HasPrefix(spec, "@") {
+		d, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron descriptor: %s", spec)
+		}
+		expanded = d
+	}
+
+	fields := strings.Fields(expanded)
+	if len(fields) != 5 {
+		return nil, ErrWrongFieldsNumber
+	}
+
+	e := &Expression{spec: spec}
+	var err error
+	if e.minute, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, fmt.Errorf("error parsing minute field: %w", err)
+	}
+	if e.hour, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, fmt.Errorf("error parsing hour field: %w", err)
+	}
+	if e.dom, err = parseField(fields[2], domBounds); err != nil {
+		return nil, fmt.Errorf("error parsing day-of-month field: %w", err)
+	}
+	if e.month, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, fmt.Errorf("error parsing month field: %w", err)
+	}
+	if e.dow, err = parseField(fields[4], dowBounds); err != nil {
+		return nil, fmt.Errorf("error parsing day-of-week field: %w", err)
+	}
+	// 7 is also accepted as sunday
+	if e.dow&(1<<7) > 0 {
+		e.dow |= 1
+	}
+
+	e.domStar = fields[2] == "*" || fields[2] == "?"
+	e.dowStar = fields[4] == "*" || fields[4] == "?"
+
+	return e, nil
+}
+
+func (e *Expression) String() string {
+	return e.spec
+}
+
+// Next returns the first activation time after t.
+// Zero time is returned when there is no activation in the next five years.
+func (e *Expression) Next(t time.Time) time.Time {
+	t = t.UTC().Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	limit := t.Add(maxLookup)
+
+	for t.Before(limit) {
+		if e.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !e.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if e.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if e.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches follows the cron convention - if both day fields are restricted, either of them has to match
+func (e *Expression) dayMatches(t time.Time) bool {
+	domMatch := e.dom&(1<<uint(t.Day())) > 0
+	dowMatch := e.dow&(1<<uint(t.Weekday())) > 0
+
+	if e.domStar || e.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func parseField(field string, b bounds) (bits uint64, err error) {
+	for _, part := range strings.Split(field, ",") {
+		pb, err := parsePart(part, b)
+		if err != nil {
+			return 0, err
+		}
+		bits |= pb
+	}
+	return bits, nil
+}
+
+func parsePart(part string, b bounds) (bits uint64, err error) {
+	rangeAndStep := strings.Split(part, "/")
+	if len(rangeAndStep) > 2 {
+		return 0, fmt.Errorf("too many slashes: %s", part)
+	}
+
+	var start, end uint
+	step := uint(1)
+
+	switch lowAndHigh := strings.Split(rangeAndStep[0], "-"); {
+	case rangeAndStep[0] == "*" || rangeAndStep[0] == "?":
+		start, end = b.min, b.max
+	case len(lowAndHigh) == 1:
+		if start, err = parseValue(lowAndHigh[0], b); err != nil {
+			return 0, err
+		}
+		end = start
+		if len(rangeAndStep) == 2 {
+			end = b.max
+		}
+	case len(lowAndHigh) == 2:
+		if start, err = parseValue(lowAndHigh[0], b); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(lowAndHigh[1], b); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("too many hyphens: %s", part)
+	}
+
+	if len(rangeAndStep) == 2 {
+		s, err := strconv.ParseUint(rangeAndStep[1], 10, 8)
+		if err != nil || s == 0 {
+			return 0, fmt.Errorf("wrong step: %s", part)
+		}
+		step = uint(s)
+	}
+
+	if start > end {
+		return 0, fmt.Errorf("beginning of range (%d) beyond end of range (%d): %s", start, end, part)
+	}
+
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	return bits, nil
+}
+
+func parseValue(v string, b bounds) (uint, error) {
+	if b.names != nil {
+		if n, ok := b.names[strings.ToLower(v)]; ok {
+			return n, nil
+		}
+	}
+
+	i, err := strconv.ParseUint(v, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("wrong value: %s", v)
+	}
+	if uint(i) < b.min || uint(i) > b.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", i, b.min, b.max)
+	}
+	return uint(i), nil
+}
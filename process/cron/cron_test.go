@@ -0,0 +1,91 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour, min, sec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	// friday
+	from := date(2021, time.January, 15, 10, 7, 30)
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{name: "step", spec: "*/15 * * * *", from: from, want: date(2021, time.January, 15, 10, 15, 0)},
+		{name: "range with step", spec: "0-30/10 * * * *", from: from, want: date(2021, time.January, 15, 10, 10, 0)},
+		{name: "list", spec: "5,45 * * * *", from: from, want: date(2021, time.January, 15, 10, 45, 0)},
+		{name: "range", spec: "0 9-17 * * *", from: from, want: date(2021, time.January, 15, 11, 0, 0)},
+		{name: "month names", spec: "0 12 * FEB-mar *", from: from, want: date(2021, time.February, 1, 12, 0, 0)},
+		{name: "day name", spec: "0 0 * * mon", from: from, want: date(2021, time.January, 18, 0, 0, 0)},
+		{name: "seven is sunday", spec: "0 0 * * 7", from: from, want: date(2021, time.January, 17, 0, 0, 0)},
+		{name: "zero is sunday", spec: "0 0 * * 0", from: from, want: date(2021, time.January, 17, 0, 0, 0)},
+		{name: "day of month or day of week", spec: "0 0 20 * fri", from: from, want: date(2021, time.January, 20, 0, 0, 0)},
+		{name: "day of month with any day of week", spec: "0 0 20 * *", from: from, want: date(2021, time.January, 20, 0, 0, 0)},
+		{name: "hourly", spec: "@hourly", from: from, want: date(2021, time.January, 15, 11, 0, 0)},
+		{name: "daily", spec: "@daily", from: from, want: date(2021, time.January, 16, 0, 0, 0)},
+		{name: "weekly", spec: "@weekly", from: from, want: date(2021, time.January, 17, 0, 0, 0)},
+		{name: "monthly", spec: "@monthly", from: from, want: date(2021, time.February, 1, 0, 0, 0)},
+		{name: "yearly", spec: "@yearly", from: from, want: date(2022, time.January, 1, 0, 0, 0)},
+		{name: "across month boundary", spec: "0 0 1 * *", from: date(2021, time.January, 31, 23, 59, 0), want: date(2021, time.February, 1, 0, 0, 0)},
+		{name: "skips months without the day", spec: "0 0 31 * *", from: date(2021, time.January, 31, 0, 0, 30), want: date(2021, time.March, 31, 0, 0, 0)},
+		{name: "across year boundary", spec: "30 23 31 12 *", from: date(2021, time.December, 31, 23, 30, 0), want: date(2022, time.December, 31, 23, 30, 0)},
+		{name: "leap day", spec: "0 0 29 2 *", from: from, want: date(2024, time.February, 29, 0, 0, 0)},
+		{name: "never", spec: "0 0 30 2 *", from: from},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := e.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr error
+	}{
+		{name: "empty", spec: " ", wantErr: ErrEmptyExpression},
+		{name: "too few fields", spec: "* * * *", wantErr: ErrWrongFieldsNumber},
+		{name: "too many fields", spec: "* * * * * *", wantErr: ErrWrongFieldsNumber},
+		{name: "minute out of range", spec: "60 * * * *"},
+		{name: "hour out of range", spec: "* 24 * * *"},
+		{name: "day of month out of range", spec: "* * 0 * *"},
+		{name: "month out of range", spec: "* * * 13 *"},
+		{name: "day of week out of range", spec: "* * * * 8"},
+		{name: "zero step", spec: "*/0 * * * *"},
+		{name: "reversed range", spec: "5-1 * * * *"},
+		{name: "too many hyphens", spec: "1-2-3 * * * *"},
+		{name: "too many slashes", spec: "*/5/2 * * * *"},
+		{name: "unknown name", spec: "* * * foo *"},
+		{name: "unknown descriptor", spec: "@every"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			if err == nil {
+				t.Fatalf("expected error parsing %q", tt.spec)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package process
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/structures"
+)
+
+// semaphore is a counting semaphore granting the slots in order of requests, so no waiter is starved
+type semaphore struct {
+	capacity uint64
+	used     uint64
+	waiters  list.List
+	lock     sync.Mutex
+}
+
+func newSemaphore(capacity uint64) *semaphore {
+	return &semaphore{capacity: capacity}
+}
+
+func (s *semaphore) Acquire(ctx context.Context) error {
+	s.lock.Lock()
+	if s.used < s.capacity && s.waiters.Len() == 0 {
+		s.used++
+		s.lock.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(ready)
+	s.lock.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		s.lock.Lock()
+		select {
+		case <-ready:
+			// slot was granted in the meantime, pass it on
+			s.lock.Unlock()
+			s.Release()
+		default:
+			s.waiters.Remove(elem)
+			s.lock.Unlock()
+		}
+		return ctx.Err()
+	}
+}
+
+func (s *semaphore) Release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if front := s.waiters.Front(); front != nil {
+		// slot goes directly to the first waiter
+		s.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	s.used--
+}
+
+// LimiterConfig sets the maximum number of concurrent runs, zero means no limit
+type LimiterConfig struct {
+	Global     uint64
+	PerNVC     uint64
+	PerAddress uint64
+}
+
+// Limiter limits the number of concurrent runs - globally, per network/version/chain and per target address.
+// Slots are acquired in the fixed order: addresses (sorted), network/version/chain, global,
+// so the run waiting for busy address does not hold the slots of others.
+type Limiter struct {
+	cfg LimiterConfig
+
+	global    *semaphore
+	nvc       map[structures.NVCKey]*semaphore
+	addresses map[string]*semaphore
+	lock      sync.Mutex
+}
+
+func NewLimiter(cfg LimiterConfig) *Limiter {
+	l := &Limiter{
+		cfg:       cfg,
+		nvc:       make(map[structures.NVCKey]*semaphore),
+		addresses: make(map[string]*semaphore),
+	}
+	if cfg.Global > 0 {
+		l.global = newSemaphore(cfg.Global)
+	}
+	return l
+}
+
+// Acquire waits for the slots of target addresses, network/version/chain and the global one.
+// Run calling the same address more than once holds a single slot of it.
+func (l *Limiter) Acquire(ctx context.Context, nv structures.NVCKey, addresses []string) (release func(), err error) {
+	var acquired []*semaphore
+	release = func() {
+		for i := len(acquired) - 1; i >= 0; i-- {
+			acquired[i].Release()
+		}
+	}
+
+	for _, sem := range l.semaphores(nv, addresses) {
+		if err := acquireObserved(ctx, sem.s, sem.limit); err != nil {
+			release()
+			return nil, err
+		}
+		acquired = append(acquired, sem.s)
+	}
+
+	return release, nil
+}
+
+type limitSemaphore struct {
+	s     *semaphore
+	limit string
+}
+
+// semaphores returns the semaphores of run in order of acquiring
+func (l *Limiter) semaphores(nv structures.NVCKey, addresses []string) (sems []limitSemaphore) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.cfg.PerAddress > 0 {
+		unique := make([]string, 0, len(addresses))
+		seen := make(map[string]bool, len(addresses))
+		for _, a := range addresses {
+			if !seen[a] {
+				seen[a] = true
+				unique = append(unique, a)
+			}
+		}
+		sort.Strings(unique)
+
+		for _, a := range unique {
+			sem, ok := l.addresses[a]
+			if !ok {
+				sem = newSemaphore(l.cfg.PerAddress)
+				l.addresses[a] = sem
+			}
+			sems = append(sems, limitSemaphore{s: sem, limit: "address"})
+		}
+	}
+
+	if l.cfg.PerNVC > 0 {
+		sem, ok := l.nvc[nv]
+		if !ok {
+			sem = newSemaphore(l.cfg.PerNVC)
+			l.nvc[nv] = sem
+		}
+		sems = append(sems, limitSemaphore{s: sem, limit: "nvc"})
+	}
+
+	if l.global != nil {
+		sems = append(sems, limitSemaphore{s: l.global, limit: "global"})
+	}
+	return sems
+}
+
+func acquireObserved(ctx context.Context, s *semaphore, limit string) error {
+	now := time.Now()
+	err := s.Acquire(ctx)
+	limiterQueueDuration.WithLabels(limit).Observe(time.Since(now).Seconds())
+	return err
+}
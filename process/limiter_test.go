@@ -0,0 +1,52 @@
+package process
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/structures"
+)
+
+func TestLimiterAddressWaitHoldsNoGlobalSlot(t *testing.T) {
+	l := NewLimiter(LimiterConfig{Global: 2, PerAddress: 1})
+	nv := structures.NVCKey{Network: "n", ChainID: "c", Version: "0.0.1"}
+
+	releaseA, err := l.Acquire(context.Background(), nv, []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waiting := make(chan struct{})
+	go func() {
+		release, err := l.Acquire(context.Background(), nv, []string{"a"})
+		if err == nil {
+			release()
+		}
+		close(waiting)
+	}()
+
+	// the run waiting for address "a" must not take the remaining global slot
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	releaseB, err := l.Acquire(ctx, nv, []string{"b"})
+	if err != nil {
+		t.Fatalf("run of other address is blocked: %v", err)
+	}
+
+	releaseB()
+	releaseA()
+	<-waiting
+}
+
+func TestLimiterSameAddressTwice(t *testing.T) {
+	l := NewLimiter(LimiterConfig{PerAddress: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	release, err := l.Acquire(ctx, structures.NVCKey{}, []string{"a", "a"})
+	if err != nil {
+		t.Fatalf("run calling the same address twice is blocked: %v", err)
+	}
+	release()
+}
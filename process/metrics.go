@@ -0,0 +1,11 @@
+package process
+
+import "github.com/figment-networks/indexing-engine/metrics"
+
+var limiterQueueDuration = metrics.MustNewHistogramWithTags(metrics.HistogramOptions{
+	Namespace: "scheduler",
+	Subsystem: "limiter",
+	Name:      "queue_duration",
+	Desc:      "Time spent waiting for concurrency slot before the run",
+	Tags:      []string{"limit"},
+})
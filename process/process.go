@@ -3,19 +3,28 @@ package process
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"math/rand"
 	"sync"
 	"time"
 
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
 	"github.com/figment-networks/indexer-scheduler/structures"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type Marker interface {
-	MarkFinished(ctx context.Context, id uuid.UUID) error
+	MarkFinished(ctx context.Context, runID, id uuid.UUID) error
+	MarkFailed(ctx context.Context, runID, id uuid.UUID, lastErr error) error
+	SaveRunState(ctx context.Context, runID, id uuid.UUID, state structures.RunState) error
+}
+
+// Gate decides if the scheduled run should be skipped, giving the reason of skipping
+type Gate interface {
+	Skip(rc structures.RunConfig, t time.Time) (skip bool, reason string)
 }
 
 type Runner interface {
@@ -23,10 +32,31 @@ type Runner interface {
 	Name() string
 }
 
+// TargetResolver is implemented by runners calling targets. Targets are chosen before the run and passed in its context,
+// so the slots of their addresses are acquired first and waiting for them does not count into the timeout.
+type TargetResolver interface {
+	ResolveTargets(ctx context.Context, rcp structures.RunConfigParams) []structures.Target
+}
+
+// execLock is the execution lock of single schedule, users counts the runs holding or waiting for it
+type execLock struct {
+	sync.Mutex
+	users int
+}
+
+// Skipped describes the run that was skipped by one of the gates
+type Skipped struct {
+	ID     uuid.UUID `json:"id"`
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
 type Running struct {
-	Id uuid.UUID
+	Id     uuid.UUID
+	Config structures.RunConfig
 
 	CancelFunc context.CancelFunc
+	done       chan struct{}
 }
 
 type Scheduler struct {
@@ -34,84 +64,412 @@ type Scheduler struct {
 	marker  Marker
 	runlock sync.Mutex
 	logger  *zap.Logger
+
+	// execution locks prevent concurrent runs of the same schedule
+	execLocks    map[uuid.UUID]*execLock
+	execLocksMap sync.Mutex
+
+	// closing is set on shutdown, no new schedules are started after that
+	closing  bool
+	shutdown chan struct{}
+
+	gates []Gate
+
+	// skipped keeps the reason of the latest skipped run, until the schedule runs again
+	skipped     map[uuid.UUID]Skipped
+	skippedLock sync.Mutex
+
+	limiter *Limiter
+
+	// catchUpSpacing is the minimum time between starts of runs, while the task is catching up. 0 disables catching up.
+	catchUpSpacing time.Duration
 }
 
 func NewScheduler(logger *zap.Logger, marker Marker) *Scheduler {
 	return &Scheduler{
-		running: make(map[uuid.UUID]Running),
-		logger:  logger,
-		marker:  marker,
+		running:   make(map[uuid.UUID]Running),
+		execLocks: make(map[uuid.UUID]*execLock),
+		shutdown:  make(chan struct{}),
+		skipped:   make(map[uuid.UUID]Skipped),
+		logger:    logger,
+		marker:    marker,
 	}
 }
 
-func (s *Scheduler) Run(ctx context.Context, id uuid.UUID, d time.Duration, rcp structures.RunConfigParams, r Runner) {
+func (s *Scheduler) Run(ctx context.Context, rc structures.RunConfig, r Runner) {
+	id := rc.ID
+	sch, err := NewSchedule(rc)
+	if err != nil {
+		s.logger.Error("[Process] Error creating schedule", zap.String("id", id.String()), zap.Error(err))
+		s.markFailed(ctx, rc.RunID, id, fmt.Errorf("error creating schedule: %w", err))
+		return
+	}
+
+	rcp := rc.Params()
+
+	next, catchUpMissed := resumeRun(sch, rc, time.Now())
+	if catchUpMissed {
+		s.logger.Info("[Process] Running missed activations", zap.String("id", id.String()), zap.Time("since", next))
+	}
+	if next.IsZero() {
+		s.logger.Warn("[Process] There is no next activation of schedule", zap.String("id", id.String()), zap.String("cron", rc.Cron))
+		s.markFinished(ctx, rc.RunID, id)
+		return
+	}
+
+	if afterEnd(rc, next) {
+		s.logger.Info("[Process] Schedule is past its end, finishing", zap.String("id", id.String()), zap.Time("end_at", rc.EndAt))
+		s.markFinished(ctx, rc.RunID, id)
+		return
+	}
+
 	cCtx, cancel := context.WithCancel(ctx)
-	tckr := time.NewTicker(d)
 
 	s.runlock.Lock()
+	if _, ok := s.running[id]; ok || s.closing {
+		s.runlock.Unlock()
+		cancel()
+		return
+	}
+	done := make(chan struct{})
 	s.running[id] = Running{
 		Id:         id,
+		Config:     rc,
 		CancelFunc: cancel,
+		done:       done,
 	}
 	s.runlock.Unlock()
 
-	var backoffCounter uint64
+	tmr := time.NewTimer(time.Until(next))
+	var failures uint64
+	backoffCounter := rc.BackoffIteration
+	lastRun := rc.LastRun
 RunLoop:
 	for {
 		select {
-		case <-tckr.C:
-			backoff, err := r.Run(cCtx, rcp)
-
-			if err != nil && err == io.EOF { // finish on end of processing
-				tckr.Stop()
-				s.marker.MarkFinished(ctx, id)
+		case <-tmr.C:
+			// select picks randomly among ready cases, so the due timer may win over closed shutdown
+			select {
+			case <-s.shutdown:
 				break RunLoop
+			default:
 			}
 
-			if backoff {
-				backoffCounter++
-				dur := calcBackoff(d, backoffCounter)
-				s.logger.Info("[Process] Setting backoff", zap.Duration("duration", dur))
-				tckr.Reset(dur)
-			} else if backoffCounter > 0 {
-				s.logger.Info("[Process] Resetting backoff", zap.Duration("duration", d))
-				backoffCounter = 0
-				tckr.Reset(d)
-			}
+			if skip, reason := s.gated(rc, time.Now()); skip {
+				s.logger.Info("[Process] Skipping run", zap.String("id", id.String()), zap.String("network", rcp.Network), zap.String("chain_id", rcp.ChainID), zap.String("task_id", rcp.TaskID), zap.String("reason", reason))
+				s.setSkipped(id, reason)
+				next = nextAfter(sch, next, time.Now())
+			} else {
+				s.setSkipped(id, "")
+				started := time.Now()
+				lastRun = started
+				backoff, err := s.execute(cCtx, id, rcp, rc.Timeout, r)
+
+				behind := errors.Is(err, structures.ErrBehind)
+				if behind {
+					err = nil
+				}
+
+				if err != nil && err == io.EOF { // finish on end of processing
+					s.markFinished(ctx, rc.RunID, id)
+					break RunLoop
+				}
+
+				// errors caused by stopping the schedule are not failures
+				if cCtx.Err() != nil {
+					break RunLoop
+				}
+
+				if err != nil {
+					failures++
+				} else {
+					failures = 0
+				}
 
-			if err != nil {
-				var rErr *structures.RunError
-				s.logger.Error("[Process] Error running task", zap.String("id", id.String()), zap.String("network", rcp.Network), zap.String("chain_id", rcp.ChainID), zap.String("task_id", rcp.TaskID), zap.String("version", rcp.Version), zap.Error(err))
-				if errors.As(err, &rErr) {
-					if !rErr.IsRecoverable() {
-						tckr.Stop()
+				now := time.Now()
+				// with retry policy set, errors are also retried with backoff
+				if backoff || (err != nil && rc.RetryPolicy != nil) {
+					backoffCounter++
+					var dur time.Duration
+					if rc.RetryPolicy != nil {
+						dur = retryDelay(*rc.RetryPolicy, baseInterval(sch, now), backoffCounter)
+					} else {
+						dur = calcBackoff(baseInterval(sch, now), backoffCounter)
+					}
+					s.logger.Info("[Process] Setting backoff", zap.Duration("duration", dur))
+					next = now.Add(dur)
+				} else {
+					if backoffCounter > 0 {
+						s.logger.Info("[Process] Resetting backoff")
+						backoffCounter = 0
+					}
+					switch {
+					case catchUpMissed:
+						next = sch.Next(next)
+						catchUpMissed = !next.IsZero() && !next.After(now)
+					case behind && s.catchUpSpacing > 0:
+						s.logger.Debug("[Process] Catching up", zap.String("id", id.String()), zap.String("task_id", rcp.TaskID))
+						next = started.Add(s.catchUpSpacing)
+					default:
+						next = nextAfter(sch, next, now)
+					}
+				}
+
+				if err != nil {
+					var rErr *structures.RunError
+					s.logger.Error("[Process] Error running task", zap.String("id", id.String()), zap.String("network", rcp.Network), zap.String("chain_id", rcp.ChainID), zap.String("task_id", rcp.TaskID), zap.String("version", rcp.Version), zap.Error(err))
+					if errors.As(err, &rErr) && !rErr.IsRecoverable() {
+						s.markFailed(ctx, rc.RunID, id, err)
+						break RunLoop
+					}
+
+					if rc.RetryPolicy != nil && rc.RetryPolicy.MaxFailures > 0 && failures >= rc.RetryPolicy.MaxFailures {
+						s.logger.Error("[Process] Reached maximum number of consecutive failures, stopping schedule", zap.String("id", id.String()), zap.Uint64("failures", failures))
+						s.markFailed(ctx, rc.RunID, id, fmt.Errorf("reached maximum number of consecutive failures (%d): %w", failures, err))
 						break RunLoop
 					}
 				}
 			}
 
+			if next.IsZero() {
+				s.logger.Warn("[Process] There is no next activation of schedule", zap.String("id", id.String()), zap.String("cron", rc.Cron))
+				s.markFinished(ctx, rc.RunID, id)
+				break RunLoop
+			}
+
+			if afterEnd(rc, next) {
+				s.logger.Info("[Process] Schedule reached its end, finishing", zap.String("id", id.String()), zap.Time("end_at", rc.EndAt))
+				s.markFinished(ctx, rc.RunID, id)
+				break RunLoop
+			}
+
+			if err := s.marker.SaveRunState(ctx, rc.RunID, id, structures.RunState{LastRun: lastRun, NextRun: next, BackoffIteration: backoffCounter}); err != nil {
+				if errors.Is(err, params.ErrNotFound) {
+					s.logger.Warn("[Process] Schedule was removed or taken over by other instance, stopping", zap.String("id", id.String()))
+					break RunLoop
+				}
+				s.logger.Warn("[Process] Error saving state of schedule", zap.String("id", id.String()), zap.Error(err))
+			}
+			tmr.Reset(time.Until(next))
+		case <-s.shutdown:
+			break RunLoop
 		case <-cCtx.Done():
-			tckr.Stop()
 			break RunLoop
 		case <-ctx.Done():
-			tckr.Stop()
 			break RunLoop
 		}
 	}
+	tmr.Stop()
+	cancel()
 
 	s.runlock.Lock()
 	delete(s.running, id)
 	s.runlock.Unlock()
+	s.setSkipped(id, "")
+	close(done)
+}
+
+// SetLimiter sets the limiter of concurrent runs, it has to be called before running any schedule
+func (s *Scheduler) SetLimiter(l *Limiter) {
+	s.limiter = l
+}
+
+// SetCatchUp sets the minimum spacing of runs of tasks that are behind, 0 disables catching up.
+// It has to be called before running any schedule.
+func (s *Scheduler) SetCatchUp(spacing time.Duration) {
+	s.catchUpSpacing = spacing
+}
+
+// AddGate adds a gate checked before every scheduled run. Runs triggered manually are not gated.
+func (s *Scheduler) AddGate(g Gate) {
+	s.runlock.Lock()
+	defer s.runlock.Unlock()
+
+	s.gates = append(s.gates, g)
+}
+
+func (s *Scheduler) gated(rc structures.RunConfig, t time.Time) (skip bool, reason string) {
+	s.runlock.Lock()
+	gates := s.gates
+	s.runlock.Unlock()
+
+	for _, g := range gates {
+		if skip, reason := g.Skip(rc, t); skip {
+			return true, reason
+		}
+	}
+	return false, ""
+}
+
+func (s *Scheduler) setSkipped(id uuid.UUID, reason string) {
+	s.skippedLock.Lock()
+	defer s.skippedLock.Unlock()
+
+	if reason == "" {
+		delete(s.skipped, id)
+		return
+	}
+	s.skipped[id] = Skipped{ID: id, Reason: reason, At: time.Now()}
+}
+
+// Skipped lists schedules which latest run was skipped, with the reason
+func (s *Scheduler) Skipped() []Skipped {
+	s.skippedLock.Lock()
+	defer s.skippedLock.Unlock()
+
+	list := make([]Skipped, 0, len(s.skipped))
+	for _, sk := range s.skipped {
+		list = append(list, sk)
+	}
+	return list
+}
+
+func afterEnd(rc structures.RunConfig, next time.Time) bool {
+	return !rc.EndAt.IsZero() && next.After(rc.EndAt)
+}
+
+func (s *Scheduler) markFinished(ctx context.Context, runID, id uuid.UUID) {
+	if err := s.marker.MarkFinished(ctx, runID, id); err != nil {
+		s.logger.Error("[Process] Error setting state finished", zap.String("id", id.String()), zap.Error(err))
+	}
+}
+
+func (s *Scheduler) markFailed(ctx context.Context, runID, id uuid.UUID, lastErr error) {
+	if err := s.marker.MarkFailed(ctx, runID, id, lastErr); err != nil {
+		s.logger.Error("[Process] Error setting state failed", zap.String("id", id.String()), zap.Error(err))
+	}
 }
 
+// RunOnce executes runner once, outside of the regular schedule.
+// It never runs in parallel with scheduled execution of the same schedule.
+func (s *Scheduler) RunOnce(ctx context.Context, rc structures.RunConfig, r Runner) (backoff bool, err error) {
+	s.logger.Info("[Process] Triggering single run", zap.String("id", rc.ID.String()), zap.String("network", rc.Network), zap.String("chain_id", rc.ChainID), zap.String("task_id", rc.TaskID))
+	backoff, err = s.execute(ctx, rc.ID, rc.Params(), rc.Timeout, r)
+	if errors.Is(err, structures.ErrBehind) {
+		err = nil
+	}
+	return backoff, err
+}
+
+// execute runs the runner with deadline set to timeout, zero timeout means no deadline
+func (s *Scheduler) execute(ctx context.Context, id uuid.UUID, rcp structures.RunConfigParams, timeout time.Duration, r Runner) (backoff bool, err error) {
+	s.execLocksMap.Lock()
+	l, ok := s.execLocks[id]
+	if !ok {
+		l = &execLock{}
+		s.execLocks[id] = l
+	}
+	l.users++
+	s.execLocksMap.Unlock()
+
+	l.Lock()
+	defer func() {
+		l.Unlock()
+		// lock is removed with its last user, so the map does not grow with every schedule ever run
+		s.execLocksMap.Lock()
+		if l.users--; l.users == 0 {
+			delete(s.execLocks, id)
+		}
+		s.execLocksMap.Unlock()
+	}()
+
+	var addresses []string
+	if tr, ok := r.(TargetResolver); ok {
+		targets := tr.ResolveTargets(ctx, rcp)
+		for _, t := range targets {
+			addresses = append(addresses, t.Address)
+		}
+		ctx = structures.WithTargets(ctx, targets)
+	}
+
+	// waiting for the slots does not count into the timeout
+	if s.limiter != nil {
+		release, err := s.limiter.Acquire(ctx, structures.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID}, addresses)
+		if err != nil {
+			return false, &structures.RunError{Contents: fmt.Errorf("error waiting for concurrency slot: %w", err)}
+		}
+		defer release()
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return r.Run(ctx, rcp)
+}
+
+// Stop cancels the schedule and waits until it's finished or ctx is done
 func (s *Scheduler) Stop(ctx context.Context, id uuid.UUID) {
+	s.runlock.Lock()
+	r, ok := s.running[id]
+	s.runlock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	r.CancelFunc()
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+	}
+}
+
+// Shutdown stops accepting new runs and waits for the in-flight ones until ctx is done.
+// Runs that are still in progress after that are cancelled. It returns ids of all the schedules that were running.
+func (s *Scheduler) Shutdown(ctx context.Context) (stopped []uuid.UUID) {
+	s.runlock.Lock()
+	if !s.closing {
+		s.closing = true
+		close(s.shutdown)
+	}
+	running := make([]Running, 0, len(s.running))
+	for _, r := range s.running {
+		running = append(running, r)
+	}
+	s.runlock.Unlock()
+
+	for _, r := range running {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			s.logger.Warn("[Process] Cancelling in-flight run", zap.String("id", r.Id.String()))
+			r.CancelFunc()
+			<-r.done
+		}
+		stopped = append(stopped, r.Id)
+	}
+
+	return stopped
+}
+
+// RunningConfig returns the config that schedule is currently running with
+func (s *Scheduler) RunningConfig(id uuid.UUID) (rc structures.RunConfig, ok bool) {
 	s.runlock.Lock()
 	defer s.runlock.Unlock()
 
 	r, ok := s.running[id]
-	if ok {
-		r.CancelFunc()
+	return r.Config, ok
+}
+
+func (s *Scheduler) IsRunning(id uuid.UUID) bool {
+	s.runlock.Lock()
+	defer s.runlock.Unlock()
+
+	_, ok := s.running[id]
+	return ok
+}
+
+func (s *Scheduler) RunningIDs() (ids []uuid.UUID) {
+	s.runlock.Lock()
+	defer s.runlock.Unlock()
+
+	for id := range s.running {
+		ids = append(ids, id)
 	}
+	return ids
 }
 
 var backoffsMultipliers = []float64{.5, 1, 1, 1.5, 2, 4, 4, 8, 8, 16, 16, 32}
@@ -0,0 +1,79 @@
+package process
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/structures"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type nopMarker struct{}
+
+func (nopMarker) MarkFinished(ctx context.Context, runID, id uuid.UUID) error { return nil }
+func (nopMarker) MarkFailed(ctx context.Context, runID, id uuid.UUID, lastErr error) error {
+	return nil
+}
+func (nopMarker) SaveRunState(ctx context.Context, runID, id uuid.UUID, state structures.RunState) error {
+	return nil
+}
+
+// behindRunner reports being behind for the first `behind` runs, every run is announced on runs channel
+type behindRunner struct {
+	behind int
+
+	count int32
+	runs  chan int
+}
+
+func (r *behindRunner) Name() string { return "behind" }
+
+func (r *behindRunner) Run(ctx context.Context, rcp structures.RunConfigParams) (bool, error) {
+	n := int(atomic.AddInt32(&r.count, 1))
+	r.runs <- n
+	if n <= r.behind {
+		return false, structures.ErrBehind
+	}
+	return false, nil
+}
+
+func TestRunCatchUpReturnsToInterval(t *testing.T) {
+	s := NewScheduler(zap.NewNop(), nopMarker{})
+	s.SetCatchUp(time.Millisecond)
+
+	r := &behindRunner{behind: 3, runs: make(chan int, 10)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the first run is the missed one, so it starts right away. The interval is long enough that only catch-up runs can follow it.
+	rc := structures.RunConfig{ID: uuid.New(), Network: "n", ChainID: "c", Version: "0.0.1", Kind: "behind", TaskID: "t", Duration: time.Hour,
+		NextRun: time.Now(), MissedRuns: structures.MissedRunOnce}
+	finished := make(chan struct{})
+	go func() {
+		s.Run(ctx, rc, r)
+		close(finished)
+	}()
+
+	// runs that were behind are followed right away
+	for i := 1; i <= r.behind+1; i++ {
+		select {
+		case n := <-r.runs:
+			if n != i {
+				t.Fatalf("run %d reported as %d", i, n)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("run %d was not started by catch-up", i)
+		}
+	}
+
+	cancel()
+	<-finished
+
+	// once caught up, the next run waits for the regular interval
+	if n := atomic.LoadInt32(&r.count); n != int32(r.behind+1) {
+		t.Errorf("schedule ran %d times, want %d", n, r.behind+1)
+	}
+}
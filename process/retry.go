@@ -0,0 +1,52 @@
+package process
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/structures"
+)
+
+const defaultRetryMultiplier = 2
+
+// maxRetryDelay caps the delay of policies without maximum, keeping it far from overflowing time.Duration
+const maxRetryDelay = 24 * time.Hour
+
+// retryDelay calculates the delay of backoffIteration (starting from 1) according to the policy.
+// When policy has no base delay set, the base is taken from the schedule.
+func retryDelay(rp structures.RetryPolicy, base time.Duration, backoffIteration uint64) time.Duration {
+	if rp.BaseDelay > 0 {
+		base = rp.BaseDelay
+	}
+
+	multiplier := rp.Multiplier
+	if multiplier == 0 {
+		multiplier = defaultRetryMultiplier
+	}
+
+	delay := float64(base)
+	if backoffIteration > 1 {
+		delay *= math.Pow(multiplier, float64(backoffIteration-1))
+	}
+	ceiling := maxRetryDelay
+	if rp.MaxDelay > 0 {
+		ceiling = rp.MaxDelay
+	}
+	// clamped before conversion, so long failure streaks (+Inf) and NaN do not overflow
+	if !(delay <= float64(ceiling)) {
+		delay = float64(ceiling)
+	}
+	if delay < 0 {
+		delay = 0
+	}
+
+	switch rp.Jitter {
+	case structures.JitterFull:
+		delay = delay * rand.Float64()
+	case structures.JitterEqual:
+		delay = delay/2 + delay/2*rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
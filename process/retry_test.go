@@ -0,0 +1,37 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/structures"
+)
+
+func TestRetryDelayLargeIteration(t *testing.T) {
+	tests := []struct {
+		name string
+		rp   structures.RetryPolicy
+		want time.Duration
+	}{
+		{name: "default multiplier", rp: structures.RetryPolicy{}, want: maxRetryDelay},
+		{name: "large multiplier", rp: structures.RetryPolicy{Multiplier: 1e300}, want: maxRetryDelay},
+		{name: "max delay", rp: structures.RetryPolicy{Multiplier: 1e300, MaxDelay: time.Minute}, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, it := range []uint64{64, 1000, 1 << 40} {
+				if got := retryDelay(tt.rp, time.Second, it); got != tt.want {
+					t.Errorf("retryDelay(iteration %d) = %s, want %s", it, got, tt.want)
+				}
+			}
+		})
+	}
+
+	for i := 0; i < 100; i++ {
+		got := retryDelay(structures.RetryPolicy{Jitter: structures.JitterFull}, time.Second, 1<<40)
+		if got < 0 || got > maxRetryDelay {
+			t.Fatalf("retryDelay with full jitter = %s, want between 0 and %s", got, maxRetryDelay)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/process/cron"
+	"github.com/figment-networks/indexer-scheduler/structures"
+	"github.com/google/uuid"
+)
+
+var ErrNoSchedule = errors.New("either interval or cron has to be set")
+
+// Schedule returns the next activation time after the given time
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// IntervalSchedule activates in equal intervals
+type IntervalSchedule struct {
+	Interval time.Duration
+}
+
+func (is IntervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(is.Interval)
+}
+
+// shiftedSchedule moves every activation of the schedule by constant shift
+type shiftedSchedule struct {
+	Schedule
+	shift time.Duration
+}
+
+func (ss shiftedSchedule) Next(t time.Time) time.Time {
+	next := ss.Schedule.Next(t.Add(-ss.shift))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(ss.shift)
+}
+
+// NewSchedule creates schedule from run config. Cron expression takes precedence over the interval.
+// Cron activations are shifted by the phase derived from schedule jitter.
+func NewSchedule(rc structures.RunConfig) (Schedule, error) {
+	if rc.Cron != "" {
+		e, err := cron.Parse(rc.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing cron expression: %w", err)
+		}
+		if p := phase(rc.ID, rc.Jitter); p > 0 {
+			return shiftedSchedule{Schedule: e, shift: p}, nil
+		}
+		return e, nil
+	}
+
+	if rc.Duration <= 0 {
+		return nil, ErrNoSchedule
+	}
+
+	return IntervalSchedule{Interval: rc.Duration}, nil
+}
+
+// phase returns deterministic shift in range [0, jitter), derived from schedule id.
+// The same schedule always gets the same phase, while different ones are spread evenly.
+func phase(id uuid.UUID, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write(id[:])
+	return time.Duration(h.Sum64() % uint64(jitter))
+}
+
+// startBound returns the time schedule cannot start before, zero means it can start right away
+func startBound(rc structures.RunConfig, now time.Time) time.Time {
+	bound := rc.StartAt
+	if rc.StartOffset > 0 {
+		if offset := now.Add(rc.StartOffset); offset.After(bound) {
+			bound = offset
+		}
+	}
+	return bound
+}
+
+// firstRun returns the first activation of schedule, taking start time, offset and jitter into account
+func firstRun(sch Schedule, rc structures.RunConfig, now time.Time) time.Time {
+	next := firstActivation(sch, startBound(rc, now), now)
+	if next.IsZero() {
+		return next
+	}
+
+	// following interval activations are counted from the first one, so they keep the phase
+	if _, ok := sch.(IntervalSchedule); ok {
+		next = next.Add(phase(rc.ID, rc.Jitter))
+	}
+	return next
+}
+
+// firstActivation returns the first activation of schedule, that is not before startAt
+func firstActivation(sch Schedule, startAt, now time.Time) time.Time {
+	if !startAt.After(now) {
+		return sch.Next(now)
+	}
+
+	if _, ok := sch.(IntervalSchedule); ok {
+		return startAt
+	}
+	return sch.Next(startAt.Add(-time.Nanosecond))
+}
+
+// nextAfter returns first activation of schedule that is after now, counting from the previous activation
+func nextAfter(sch Schedule, previous, now time.Time) time.Time {
+	next := sch.Next(previous)
+	for !next.IsZero() && !next.After(now) {
+		next = sch.Next(next)
+	}
+	return next
+}
+
+// baseInterval returns the distance between two upcoming activations, used as a base for backoff
+func baseInterval(sch Schedule, now time.Time) time.Duration {
+	if is, ok := sch.(IntervalSchedule); ok {
+		return is.Interval
+	}
+
+	next := sch.Next(now)
+	return sch.Next(next).Sub(next)
+}
+
+// resumeRun returns the first activation of schedule that was running before, continuing from its planned next run.
+// Activations missed in the meantime are handled according to the schedule policy,
+// catchUp reports that the following missed activations should be run one after another.
+func resumeRun(sch Schedule, rc structures.RunConfig, now time.Time) (next time.Time, catchUp bool) {
+	if rc.NextRun.IsZero() {
+		return firstRun(sch, rc, now), false
+	}
+
+	if rc.NextRun.After(now) {
+		return rc.NextRun, false
+	}
+
+	switch rc.MissedRuns {
+	case structures.MissedRunOnce:
+		// the latest missed activation, so the following ones keep the phase
+		next = rc.NextRun
+		for n := sch.Next(next); !n.IsZero() && !n.After(now); n = sch.Next(n) {
+			next = n
+		}
+		return next, false
+	case structures.MissedRunAll:
+		return rc.NextRun, true
+	default:
+		return nextAfter(sch, rc.NextRun, now), false
+	}
+}
@@ -0,0 +1,197 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/runner/callback/monitor"
+	"github.com/figment-networks/indexer-scheduler/runner/callback/persistence"
+	"github.com/figment-networks/indexer-scheduler/runner/callback/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+const RunnerName = "callback"
+
+type CallbackTransporter interface {
+	Call(ctx context.Context, t coreStructs.Target, cReq structures.CallRequest) (result json.RawMessage, backoff bool, err error)
+}
+
+type TargetGetter interface {
+	Get(nv coreStructs.NVCKey) (t coreStructs.Target, ok bool)
+}
+
+// Client is a generic runner, calling the method or endpoint configured in the schedule config
+type Client struct {
+	store     *persistence.CallbackStorageTransport
+	transport map[string]CallbackTransporter
+	dest      TargetGetter
+	logger    *zap.Logger
+	m         *monitor.Monitor
+}
+
+func NewClient(logger *zap.Logger, store *persistence.CallbackStorageTransport, ac auth.AuthCredentials, dest TargetGetter) *Client {
+	return &Client{
+		store:     store,
+		dest:      dest,
+		logger:    logger,
+		transport: make(map[string]CallbackTransporter),
+		m:         monitor.NewMonitor(store, ac),
+	}
+}
+
+func (c *Client) AddTransport(typeS string, tr CallbackTransporter) {
+	c.transport[typeS] = tr
+}
+
+func (c *Client) Name() string {
+	return RunnerName
+}
+
+// ResolveTargets chooses the target of the next run
+func (c *Client) ResolveTargets(ctx context.Context, rcp coreStructs.RunConfigParams) []coreStructs.Target {
+	if t, ok := c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID}); ok {
+		return []coreStructs.Target{t}
+	}
+	return nil
+}
+
+func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (backoff bool, err error) {
+	cfg, err := structures.ConfigFromMapInterface(rcp.Config)
+	if err != nil {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error in config [%s]: %w", RunnerName, err), Unrecoverable: true}
+	}
+
+	latest, err := c.store.GetLatest(ctx, rcp)
+	if err != nil && err != params.ErrNotFound {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error getting data from store GetLatest [%s]:  %w", RunnerName, err)}
+	}
+
+	p, err := renderParams(cfg.Params, map[string]interface{}{
+		"network":  rcp.Network,
+		"chain_id": rcp.ChainID,
+		"version":  rcp.Version,
+		"task_id":  rcp.TaskID,
+		"height":   latest.Height,
+		"time":     latest.Time,
+		"last":     latest.Data,
+	})
+	if err != nil {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error rendering params [%s]: %w", RunnerName, err), Unrecoverable: true}
+	}
+
+	t, ok := coreStructs.TargetFromContext(ctx, 0)
+	if !ok {
+		t, ok = c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID})
+	}
+	if !ok {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error getting response:  %w", coreStructs.ErrNoDestinationAvailable)}
+	}
+
+	tr, ok := c.transport[t.ConnType]
+	if !ok {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("no such transport of callback as :  %s", t.ConnType)}
+	}
+
+	result, backoff, err := tr.Call(ctx, t, structures.CallRequest{Method: cfg.Method, Endpoint: cfg.Endpoint, Params: p})
+	if err == nil && backoff {
+		// still processing, nothing to store
+		return true, nil
+	}
+
+	// on error the last known height and data are kept
+	crec := structures.CallbackRecord{Height: latest.Height, Data: latest.Data}
+	if err == nil {
+		err = extract(result, cfg, &crec)
+	}
+
+	if err != nil {
+		crec.Error = []byte(err.Error())
+		crec.ErrorClass = coreStructs.ClassifyError(ctx, err)
+		backoff = true
+	}
+
+	c.logger.Info("[Callback] Response ",
+		zap.String("runner", RunnerName),
+		zap.String("network", rcp.Network),
+		zap.String("chain_id", rcp.ChainID),
+		zap.String("task_id", rcp.TaskID),
+		zap.Uint64("height", crec.Height),
+		zap.String("error", string(crec.Error)),
+	)
+
+	sCtx, sCancel := coreStructs.StoreContext()
+	defer sCancel()
+	if err2 := c.store.SetLatest(sCtx, rcp, crec); err2 != nil {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error writing last record SetLatest [%s]:  %w", RunnerName, err2)}
+	}
+
+	if err != nil {
+		return backoff, &coreStructs.RunError{Contents: fmt.Errorf("error calling [%s]:  %w", RunnerName, err)}
+	}
+
+	return false, nil
+}
+
+// extract takes configured fields out of the response
+func extract(result json.RawMessage, cfg structures.Config, crec *structures.CallbackRecord) error {
+	if len(cfg.Persist) == 0 && cfg.HeightField == "" {
+		return nil
+	}
+
+	var resp interface{}
+	dec := json.NewDecoder(bytes.NewReader(result))
+	dec.UseNumber()
+	if err := dec.Decode(&resp); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	if cfg.HeightField != "" {
+		v, ok := lookup(resp, cfg.HeightField)
+		if !ok {
+			return fmt.Errorf("response is missing height field %q", cfg.HeightField)
+		}
+		h, err := toHeight(v)
+		if err != nil {
+			return fmt.Errorf("error reading height field %q: %w", cfg.HeightField, err)
+		}
+		crec.Height = h
+	}
+
+	crec.Data = make(map[string]interface{}, len(cfg.Persist))
+	for _, field := range cfg.Persist {
+		if v, ok := lookup(resp, field); ok {
+			store(crec.Data, field, v)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) RegisterHandles(mux *http.ServeMux) {
+	c.m.RegisterHandles(mux)
+}
+
+// LatestStatus returns the state of the latest stored run of the task
+func (c *Client) LatestStatus(ctx context.Context, rcp coreStructs.RunConfigParams) (coreStructs.LatestStatus, error) {
+	latest, err := c.store.GetLatest(ctx, rcp)
+	if err != nil {
+		return coreStructs.LatestStatus{}, err
+	}
+
+	return coreStructs.LatestStatus{
+		Height:    latest.Height,
+		Time:      latest.Time,
+		Succeeded: len(latest.Error) == 0,
+	}, nil
+}
+
+// PurgeHistory removes all the stored runs of given task
+func (c *Client) PurgeHistory(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return c.store.Purge(ctx, rcp)
+}
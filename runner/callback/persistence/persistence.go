@@ -0,0 +1,41 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/figment-networks/indexer-scheduler/runner/callback/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+)
+
+type PDriver interface {
+	GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.CallbackRecord, error)
+	SetLatest(ctx context.Context, rcp coreStructs.RunConfigParams, latest structures.CallbackRecord) error
+	GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (cRec []structures.CallbackRecord, err error)
+	Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error
+}
+
+type CallbackStorageTransport struct {
+	Driver PDriver
+}
+
+func NewCallbackStorageTransport(driver PDriver) *CallbackStorageTransport {
+	return &CallbackStorageTransport{
+		Driver: driver,
+	}
+}
+
+func (s *CallbackStorageTransport) GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.CallbackRecord, error) {
+	return s.Driver.GetLatest(ctx, rcp)
+}
+
+func (s *CallbackStorageTransport) SetLatest(ctx context.Context, rcp coreStructs.RunConfigParams, latest structures.CallbackRecord) error {
+	return s.Driver.SetLatest(ctx, rcp, latest)
+}
+
+func (s *CallbackStorageTransport) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (cRec []structures.CallbackRecord, err error) {
+	return s.Driver.GetRuns(ctx, kind, network, chainID, taskID, limit, offset)
+}
+
+func (s *CallbackStorageTransport) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return s.Driver.Purge(ctx, rcp)
+}
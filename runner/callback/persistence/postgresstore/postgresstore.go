@@ -0,0 +1,124 @@
+package postgresstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/runner/callback/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+)
+
+type Driver struct {
+	db *sql.DB
+}
+
+func NewDriver(db *sql.DB) *Driver {
+	return &Driver{
+		db: db,
+	}
+}
+
+func (d *Driver) GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams) (cRec structures.CallbackRecord, err error) {
+	dataJSON := []byte{}
+	row := d.db.QueryRowContext(ctx, "SELECT time, height, data, error, error_class, task_id FROM schedule_callback WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5 ORDER BY time DESC LIMIT 1", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+	if err := row.Scan(&cRec.Time, &cRec.Height, &dataJSON, &cRec.Error, &cRec.ErrorClass, &cRec.TaskID); err != nil {
+		if err == sql.ErrNoRows {
+			return cRec, params.ErrNotFound
+		}
+		return cRec, err
+	}
+
+	if cRec.Data, err = structures.DecodeData(dataJSON); err != nil {
+		return cRec, err
+	}
+	return cRec, nil
+}
+
+func (d *Driver) SetLatest(ctx context.Context, rcp coreStructs.RunConfigParams, cRec structures.CallbackRecord) (err error) {
+	data := cRec.Data
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.ExecContext(ctx, "INSERT INTO schedule_callback (network, chain_id, version, kind, task_id, height, data, error, error_class) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)",
+		rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID, cRec.Height, dataJSON, cRec.Error, cRec.ErrorClass)
+	return err
+}
+
+func (d *Driver) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) (err error) {
+	_, err = d.db.ExecContext(ctx, "DELETE FROM schedule_callback WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+	return err
+}
+
+func (d *Driver) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (cRec []structures.CallbackRecord, err error) {
+	q := "SELECT time, height, data, error, error_class, task_id FROM schedule_callback "
+
+	var (
+		args   []interface{}
+		wherec []string
+		i      = 1
+	)
+
+	if network != "" {
+		wherec = append(wherec, ` network =  $`+strconv.Itoa(i))
+		args = append(args, network)
+		i++
+	}
+	if kind != "" {
+		wherec = append(wherec, ` kind =  $`+strconv.Itoa(i))
+		args = append(args, kind)
+		i++
+	}
+	if taskID != "" {
+		wherec = append(wherec, ` task_id =  $`+strconv.Itoa(i))
+		args = append(args, taskID)
+		i++
+	}
+	if chainID != "" {
+		wherec = append(wherec, ` chain_id =  $`+strconv.Itoa(i))
+		args = append(args, chainID)
+		i++
+	}
+	if len(args) > 0 {
+		q += ` WHERE `
+		q += strings.Join(wherec, " AND ")
+	}
+
+	q += ` ORDER BY time DESC LIMIT $` + strconv.Itoa(i)
+	args = append(args, limit)
+	i++
+
+	if offset > 0 {
+		q += ` OFFSET $` + strconv.Itoa(i)
+		args = append(args, offset)
+	}
+
+	rows, err := d.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rec := structures.CallbackRecord{}
+		dataJSON := []byte{}
+		if err := rows.Scan(&rec.Time, &rec.Height, &dataJSON, &rec.Error, &rec.ErrorClass, &rec.TaskID); err != nil {
+			return nil, err
+		}
+		if rec.Data, err = structures.DecodeData(dataJSON); err != nil {
+			return nil, err
+		}
+		cRec = append(cRec, rec)
+	}
+
+	return cRec, rows.Err()
+}
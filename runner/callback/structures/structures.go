@@ -0,0 +1,64 @@
+package structures
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// CallbackRecord is the result of a single run, stored in the shared history table
+type CallbackRecord struct {
+	TaskID     string                 `json:"task_id"`
+	Time       time.Time              `json:"time"`
+	Height     uint64                 `json:"height"`
+	Data       map[string]interface{} `json:"data"`
+	Error      []byte                 `json:"error"`
+	ErrorClass string                 `json:"error_class"`
+}
+
+// DecodeData decodes persisted fields of the record. Numbers are kept as json.Number, so large heights do not lose precision.
+func DecodeData(b []byte) (data map[string]interface{}, err error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err = dec.Decode(&data)
+	return data, err
+}
+
+// Config is the runner configuration, taken from schedule config
+type Config struct {
+	// Method is the name of json-rpc method, used by ws transport
+	Method string `json:"method"`
+	// Endpoint is the path appended to target address, used by http transport
+	Endpoint string `json:"endpoint"`
+
+	// Params is the template of request parameters.
+	// Strings are executed as text/template, string consisting of a single action (like `{{.height}}`) is replaced with the value of its type.
+	Params interface{} `json:"params"`
+
+	// Persist lists response fields stored after the run, nested fields are separated with dots (like `block.hash`)
+	Persist []string `json:"persist"`
+	// HeightField is the response field holding height, it's stored separately, so it can be used by dependencies
+	HeightField string `json:"height_field"`
+}
+
+func ConfigFromMapInterface(a map[string]interface{}) (cfg Config, err error) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Method == "" && cfg.Endpoint == "" {
+		return cfg, errors.New("callback requires method or endpoint")
+	}
+	return cfg, nil
+}
+
+type CallRequest struct {
+	Method   string
+	Endpoint string
+	Params   interface{}
+}
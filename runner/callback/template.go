@@ -0,0 +1,115 @@
+package callback
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// singleAction matches strings consisting of a single field action, like `{{.height}}` or `{{ .last.hash }}`
+var singleAction = regexp.MustCompile(`^\{\{\s*\.([\w.]+)\s*\}\}$`)
+
+// renderParams executes the params template with given data.
+// Strings consisting of a single field action are replaced with the value itself, so the numbers stay numbers.
+func renderParams(tmpl interface{}, data map[string]interface{}) (interface{}, error) {
+	switch v := tmpl.(type) {
+	case string:
+		if m := singleAction.FindStringSubmatch(v); m != nil {
+			value, _ := lookup(data, m[1])
+			return value, nil
+		}
+
+		t, err := template.New("params").Option("missingkey=zero").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing params template %q: %w", v, err)
+		}
+		b := &strings.Builder{}
+		if err := t.Execute(b, data); err != nil {
+			return nil, fmt.Errorf("error executing params template %q: %w", v, err)
+		}
+		return b.String(), nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, el := range v {
+			r, err := renderParams(el, data)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, el := range v {
+			r, err := renderParams(el, data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// lookup returns the field of decoded json under the path, nested fields and array indexes are separated with dots
+func lookup(v interface{}, path string) (interface{}, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch el := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = el[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(el) {
+				return nil, false
+			}
+			v = el[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// store puts the value under the path, creating nested maps, so dotted fields are found by lookup and templates.
+// Value already stored under the part of path is kept.
+func store(data map[string]interface{}, path string, v interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := data[key]
+		if !ok {
+			next = make(map[string]interface{})
+			data[key] = next
+		}
+		m, ok := next.(map[string]interface{})
+		if !ok {
+			return
+		}
+		data = m
+	}
+	data[keys[len(keys)-1]] = v
+}
+
+// toHeight converts decoded json number or numeric string to height
+func toHeight(v interface{}) (uint64, error) {
+	switch h := v.(type) {
+	case json.Number:
+		return strconv.ParseUint(h.String(), 10, 64)
+	case float64:
+		if h < 0 {
+			return 0, fmt.Errorf("height cannot be negative: %v", h)
+		}
+		return uint64(h), nil
+	case string:
+		return strconv.ParseUint(h, 10, 64)
+	default:
+		return 0, fmt.Errorf("height has unexpected type %T", v)
+	}
+}
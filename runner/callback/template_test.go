@@ -0,0 +1,82 @@
+package callback
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/figment-networks/indexer-scheduler/runner/callback/structures"
+)
+
+func TestDottedFieldRoundTrip(t *testing.T) {
+	crec := &structures.CallbackRecord{}
+	cfg := structures.Config{Persist: []string{"block.hash", "block.height", "txs.0"}}
+	if err := extract(json.RawMessage(`{"block":{"hash":"abc","height":12345678901234567890},"txs":["t1","t2"]}`), cfg, crec); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+
+	// record goes through the store as json
+	b, err := json.Marshal(crec.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := structures.DecodeData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{"last": last}
+
+	tests := []struct {
+		tmpl string
+		want interface{}
+	}{
+		{tmpl: "{{.last.block.hash}}", want: "abc"},
+		{tmpl: "{{ .last.block.height }}", want: json.Number("12345678901234567890")},
+		{tmpl: "{{.last.txs.0}}", want: "t1"},
+		{tmpl: "hash-{{.last.block.hash}}", want: "hash-abc"},
+		{tmpl: "from-{{.last.block.height}}", want: "from-12345678901234567890"},
+	}
+	for _, tt := range tests {
+		got, err := renderParams(tt.tmpl, data)
+		if err != nil {
+			t.Fatalf("renderParams(%q): %v", tt.tmpl, err)
+		}
+		if got != tt.want {
+			t.Errorf("renderParams(%q) = %v, want %v", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestExtractHeight(t *testing.T) {
+	tests := []struct {
+		result  string
+		field   string
+		want    uint64
+		wantErr bool
+	}{
+		{result: `{"height":12}`, want: 12},
+		{result: `{"height":12345678901234567890}`, want: 12345678901234567890},
+		{result: `{"height":"12345678901234567890"}`, want: 12345678901234567890},
+		{result: `{"block":{"height":7}}`, field: "block.height", want: 7},
+		{result: `{"height":-1}`, wantErr: true},
+		{result: `{"height":1.5}`, wantErr: true},
+		{result: `{"height":true}`, wantErr: true},
+		{result: `{"other":1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.result, func(t *testing.T) {
+			field := "height"
+			if tt.field != "" {
+				field = tt.field
+			}
+			crec := &structures.CallbackRecord{}
+			err := extract(json.RawMessage(tt.result), structures.Config{HeightField: field}, crec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if crec.Height != tt.want {
+				t.Errorf("height = %d, want %d", crec.Height, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/runner/callback/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+const ConnectionTypeHTTP = "http"
+
+type CallbackHTTPTransport struct {
+	client *http.Client
+	l      *zap.Logger
+}
+
+func NewCallbackHTTPTransport(l *zap.Logger) *CallbackHTTPTransport {
+	return &CallbackHTTPTransport{
+		l: l,
+		client: &http.Client{
+			Timeout: time.Second * 40,
+		},
+	}
+}
+
+// Call posts params to the endpoint of target
+func (cb CallbackHTTPTransport) Call(ctx context.Context, t coreStructs.Target, cReq structures.CallRequest) (result json.RawMessage, backoff bool, err error) {
+	cb.l.Debug("[Callback][HTTP] Calling",
+		zap.String("network", t.Network),
+		zap.String("chain_id", t.ChainID),
+		zap.String("address", t.Address),
+		zap.String("endpoint", cReq.Endpoint),
+	)
+
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	if err := enc.Encode(cReq.Params); err != nil {
+		return nil, false, &coreStructs.RunError{Contents: fmt.Errorf("error encoding request: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.Address+cReq.Endpoint, b)
+	if err != nil {
+		return nil, false, &coreStructs.RunError{Contents: fmt.Errorf("error creating request: %w", err)}
+	}
+	req.Header.Add("Content-type", "application/json")
+
+	resp, err := cb.client.Do(req)
+	if err != nil {
+		return nil, true, &coreStructs.RunError{Contents: fmt.Errorf("error getting response:  %w", err)}
+	}
+	defer resp.Body.Close()
+
+	// Still processing
+	if resp.StatusCode == http.StatusProcessing {
+		return nil, true, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, &coreStructs.RunError{Contents: fmt.Errorf("error reading response:  %w", err)}
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, true, &coreStructs.RunError{Contents: fmt.Errorf("error response (%d): %s", resp.StatusCode, string(body))}
+	}
+
+	return body, false, nil
+}
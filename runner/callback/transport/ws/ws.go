@@ -0,0 +1,79 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/conn"
+	"github.com/figment-networks/indexer-scheduler/conn/tray"
+	"github.com/figment-networks/indexer-scheduler/runner/callback/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const ConnectionTypeWS = "ws"
+
+type CallbackWSTransport struct {
+	l      *zap.Logger
+	ct     *tray.ConnTray
+	nextID uint64
+}
+
+func NewCallbackWSTransport(l *zap.Logger, ct *tray.ConnTray) *CallbackWSTransport {
+	return &CallbackWSTransport{
+		l:  l,
+		ct: ct,
+	}
+}
+
+// Call sends json-rpc request with params to the target, over connection taken from tray
+func (cb *CallbackWSTransport) Call(ctx context.Context, t coreStructs.Target, cReq structures.CallRequest) (result json.RawMessage, backoff bool, err error) {
+	cb.l.Debug("[Callback][WS] Calling",
+		zap.String("network", t.Network),
+		zap.String("chain_id", t.ChainID),
+		zap.String("address", t.Address),
+		zap.String("method", cReq.Method),
+	)
+
+	rpc, err := cb.ct.Get(ConnectionTypeWS, t.Address)
+	if err != nil {
+		return nil, true, &coreStructs.RunError{Contents: fmt.Errorf("error getting connection:  %w", err)}
+	}
+
+	params, ok := cReq.Params.([]interface{})
+	if !ok && cReq.Params != nil {
+		params = []interface{}{cReq.Params}
+	}
+
+	sID := uuid.New()
+	ch := make(chan conn.Response, 1)
+	defer rpc.CloseStream(sID.String())
+	defer close(ch)
+
+	sent := atomic.AddUint64(&cb.nextID, 1)
+	if err := rpc.Send(sID.String(), ch, sent, cReq.Method, params); err != nil {
+		return nil, true, &coreStructs.RunError{Contents: fmt.Errorf("error sending request:  %w", err)}
+	}
+
+	for {
+		select {
+		case resp := <-ch:
+			if resp.ID != sent {
+				cb.l.Warn("Outstanding message passed", zap.Any("response", resp))
+				continue
+			}
+			if resp.Error != nil {
+				return nil, true, &coreStructs.RunError{Contents: fmt.Errorf("error getting response:  %w", resp.Error)}
+			}
+			return resp.Result, false, nil
+		case <-ctx.Done():
+			return nil, true, &coreStructs.RunError{Contents: fmt.Errorf("error getting response: %w", ctx.Err())}
+		case <-time.After(time.Minute * 5):
+			return nil, true, &coreStructs.RunError{Contents: fmt.Errorf("error getting response timed out")}
+		}
+	}
+}
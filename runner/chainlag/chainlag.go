@@ -0,0 +1,249 @@
+package chainlag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag/persistence"
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+const RunnerName = "chainlag"
+
+// IndexedKind is the kind of tasks, which height is compared to the chain head
+const IndexedKind = "lastdata"
+
+type ChainLagTransporter interface {
+	GetHead(ctx context.Context, t coreStructs.Target, hReq structures.HeadRequest) (hr structures.HeadResponse, err error)
+}
+
+type TargetGetter interface {
+	Get(nv coreStructs.NVCKey) (t coreStructs.Target, ok bool)
+}
+
+// StatusGetter returns the state of the latest run of indexing task
+type StatusGetter interface {
+	LatestStatus(ctx context.Context, rcp coreStructs.RunConfigParams) (coreStructs.LatestStatus, error)
+}
+
+// Client compares the chain head, reported by the worker or configured node, with the height indexed by lastdata task
+type Client struct {
+	store     *persistence.ChainLagStorageTransport
+	transport map[string]ChainLagTransporter
+	dest      TargetGetter
+	indexed   StatusGetter
+	logger    *zap.Logger
+	creds     auth.AuthCredentials
+}
+
+func NewClient(logger *zap.Logger, store *persistence.ChainLagStorageTransport, ac auth.AuthCredentials, dest TargetGetter, indexed StatusGetter) *Client {
+	return &Client{
+		store:     store,
+		dest:      dest,
+		indexed:   indexed,
+		logger:    logger,
+		creds:     ac,
+		transport: make(map[string]ChainLagTransporter),
+	}
+}
+
+func (c *Client) AddTransport(typeS string, tr ChainLagTransporter) {
+	c.transport[typeS] = tr
+}
+
+func (c *Client) Name() string {
+	return RunnerName
+}
+
+// ResolveTargets chooses the target of the next run
+func (c *Client) ResolveTargets(ctx context.Context, rcp coreStructs.RunConfigParams) []coreStructs.Target {
+	cfg, err := structures.ConfigFromMapInterface(rcp.Config)
+	if err != nil {
+		return nil
+	}
+	if t, ok := c.target(rcp, cfg); ok {
+		return []coreStructs.Target{t}
+	}
+	return nil
+}
+
+// indexedTask returns the parameters of lastdata task, which height is measured
+func indexedTask(rcp coreStructs.RunConfigParams, cfg structures.Config) coreStructs.RunConfigParams {
+	indexedParams := coreStructs.RunConfigParams{Network: rcp.Network, ChainID: rcp.ChainID, Version: rcp.Version, Kind: IndexedKind, TaskID: rcp.TaskID}
+	if cfg.IndexedTaskID != "" {
+		indexedParams.TaskID = cfg.IndexedTaskID
+	}
+	return indexedParams
+}
+
+// target returns the node from config, or the destination of schedule
+func (c *Client) target(rcp coreStructs.RunConfigParams, cfg structures.Config) (coreStructs.Target, bool) {
+	if cfg.Address != "" {
+		return coreStructs.Target{Network: rcp.Network, ChainID: rcp.ChainID, Version: rcp.Version, Address: cfg.Address, ConnType: cfg.ConnType}, true
+	}
+	return c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID})
+}
+
+func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (backoff bool, err error) {
+	cfg, err := structures.ConfigFromMapInterface(rcp.Config)
+	if err != nil {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error in config [%s]: %w", RunnerName, err), Unrecoverable: true}
+	}
+
+	t, ok := coreStructs.TargetFromContext(ctx, 0)
+	if !ok {
+		if t, ok = c.target(rcp, cfg); !ok {
+			return false, &coreStructs.RunError{Contents: fmt.Errorf("error getting response:  %w", coreStructs.ErrNoDestinationAvailable)}
+		}
+	}
+
+	tr, ok := c.transport[t.ConnType]
+	if !ok {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("no such transport of chainlag as :  %s", t.ConnType)}
+	}
+
+	indexedParams := indexedTask(rcp, cfg)
+	lRec := structures.LagRecord{Network: rcp.Network, ChainID: rcp.ChainID, Version: rcp.Version, TaskID: indexedParams.TaskID}
+
+	hr, err := tr.GetHead(ctx, t, structures.HeadRequest{
+		Network:  rcp.Network,
+		ChainID:  rcp.ChainID,
+		Version:  rcp.Version,
+		TaskID:   rcp.TaskID,
+		Method:   cfg.Method,
+		Endpoint: cfg.Endpoint,
+	})
+	switch {
+	case err != nil:
+		lRec.Error = []byte(err.Error())
+		lRec.ErrorClass = coreStructs.ClassifyError(ctx, err)
+	case len(hr.Error) != 0:
+		lRec.Error = hr.Error
+		lRec.ErrorClass = coreStructs.ErrorClassWorker
+	default:
+		lRec.Head = hr.Height
+		// task that never ran is behind by the whole chain
+		status, err2 := c.indexed.LatestStatus(ctx, indexedParams)
+		if err2 != nil && !errors.Is(err2, params.ErrNotFound) {
+			err = fmt.Errorf("error getting indexed height: %w", err2)
+			lRec.Error = []byte(err.Error())
+			lRec.ErrorClass = coreStructs.ClassifyError(ctx, err2)
+			break
+		}
+		lRec.Height = status.Height
+		if lRec.Head > lRec.Height {
+			lRec.Lag = lRec.Head - lRec.Height
+		}
+
+		headGauge.WithLabels(rcp.Network, rcp.ChainID, rcp.Version).Set(float64(lRec.Head))
+		lagGauge.WithLabels(rcp.Network, rcp.ChainID, rcp.Version, indexedParams.TaskID).Set(float64(lRec.Lag))
+	}
+
+	c.logger.Debug("[ChainLag] Measured",
+		zap.String("network", rcp.Network),
+		zap.String("chain_id", rcp.ChainID),
+		zap.String("task_id", indexedParams.TaskID),
+		zap.Uint64("head", lRec.Head),
+		zap.Uint64("height", lRec.Height),
+		zap.Uint64("lag", lRec.Lag),
+		zap.String("error", string(lRec.Error)),
+	)
+
+	sCtx, sCancel := coreStructs.StoreContext()
+	defer sCancel()
+	if err2 := c.store.SetLag(sCtx, rcp, lRec); err2 != nil {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error writing lag SetLag [%s]:  %w", RunnerName, err2)}
+	}
+
+	if err != nil {
+		return true, &coreStructs.RunError{Contents: fmt.Errorf("error getting chain head [%s]:  %w", RunnerName, err)}
+	}
+	if len(lRec.Error) != 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// PurgeHistory removes all the stored measurements of given task
+func (c *Client) PurgeHistory(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	// measurements are stored under the task id of measured task
+	if cfg, err := structures.ConfigFromMapInterface(rcp.Config); err == nil {
+		rcp.TaskID = indexedTask(rcp, cfg).TaskID
+	}
+	return c.store.Purge(ctx, rcp)
+}
+
+func (c *Client) RegisterHandles(mux *http.ServeMux) {
+	mux.HandleFunc("/scheduler/runner/chainlag/lag", c.handlerLag)
+	mux.HandleFunc("/scheduler/runner/chainlag/listRunning", c.handlerListRunning)
+}
+
+// handlerLag lists the latest lag of every task, optionally filtered by `network` and `chain_id` query parameters
+func (c *Client) handlerLag(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	lags, err := c.store.GetLags(r.Context(), r.URL.Query().Get("network"), r.URL.Query().Get("chain_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if lags == nil {
+		lags = []structures.LagRecord{}
+	}
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(lags)
+}
+
+type ListRunningRequestPayload struct {
+	Kind    string `json:"kind"`
+	Network string `json:"network"`
+	TaskID  string `json:"task_id"`
+	ChainID string `json:"chain_id"`
+	Limit   uint64 `json:"limit"`
+	Offset  uint64 `json:"offset"`
+}
+
+func (c *Client) handlerListRunning(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	dec := json.NewDecoder(r.Body)
+	lrrp := ListRunningRequestPayload{}
+	if err := dec.Decode(&lrrp); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		enc.Encode(`{"error": "error decoding payload"}`)
+		return
+	}
+
+	runs, err := c.store.GetRuns(r.Context(), lrrp.Kind, lrrp.Network, lrrp.ChainID, lrrp.TaskID, lrrp.Limit, lrrp.Offset)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		enc.Encode(`{"error": "error getting runs"}`)
+		return
+	}
+
+	if runs == nil {
+		runs = []structures.LagRecord{}
+	}
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(runs)
+}
@@ -0,0 +1,153 @@
+package chainlag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag/persistence"
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+type memDriver struct {
+	lags []structures.LagRecord
+}
+
+func (d *memDriver) SetLag(ctx context.Context, rcp coreStructs.RunConfigParams, lRec structures.LagRecord) error {
+	d.lags = append(d.lags, lRec)
+	return nil
+}
+
+func (d *memDriver) GetLags(ctx context.Context, network, chainID string) ([]structures.LagRecord, error) {
+	return nil, nil
+}
+
+func (d *memDriver) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) ([]structures.LagRecord, error) {
+	return nil, nil
+}
+
+func (d *memDriver) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return nil
+}
+
+type staticTargets struct{}
+
+func (staticTargets) Get(nv coreStructs.NVCKey) (coreStructs.Target, bool) {
+	return coreStructs.Target{Network: nv.Network, ChainID: nv.ChainID, Version: nv.Version, ConnType: "test"}, true
+}
+
+type headTransport struct {
+	hr  structures.HeadResponse
+	err error
+}
+
+func (tr headTransport) GetHead(ctx context.Context, t coreStructs.Target, hReq structures.HeadRequest) (structures.HeadResponse, error) {
+	return tr.hr, tr.err
+}
+
+type statusGetter struct {
+	status coreStructs.LatestStatus
+	err    error
+	taskID string
+}
+
+func (sg *statusGetter) LatestStatus(ctx context.Context, rcp coreStructs.RunConfigParams) (coreStructs.LatestStatus, error) {
+	sg.taskID = rcp.TaskID
+	return sg.status, sg.err
+}
+
+func TestRunLag(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		tr          headTransport
+		sg          statusGetter
+		want        structures.LagRecord
+		wantBackoff bool
+		wantErr     bool
+	}{
+		{
+			name: "behind",
+			tr:   headTransport{hr: structures.HeadResponse{Height: 120}},
+			sg:   statusGetter{status: coreStructs.LatestStatus{Height: 100}},
+			want: structures.LagRecord{TaskID: "t", Head: 120, Height: 100, Lag: 20},
+		},
+		{
+			name: "ahead of head",
+			tr:   headTransport{hr: structures.HeadResponse{Height: 100}},
+			sg:   statusGetter{status: coreStructs.LatestStatus{Height: 105}},
+			want: structures.LagRecord{TaskID: "t", Head: 100, Height: 105},
+		},
+		{
+			name: "indexed task never ran",
+			tr:   headTransport{hr: structures.HeadResponse{Height: 120}},
+			sg:   statusGetter{err: params.ErrNotFound},
+			want: structures.LagRecord{TaskID: "t", Head: 120, Lag: 120},
+		},
+		{
+			name:   "other indexed task",
+			config: map[string]interface{}{"indexed_task_id": "indexed"},
+			tr:     headTransport{hr: structures.HeadResponse{Height: 120}},
+			sg:     statusGetter{status: coreStructs.LatestStatus{Height: 110}},
+			want:   structures.LagRecord{TaskID: "indexed", Head: 120, Height: 110, Lag: 10},
+		},
+		{
+			name:        "worker error",
+			tr:          headTransport{hr: structures.HeadResponse{Error: []byte("no head")}},
+			want:        structures.LagRecord{TaskID: "t", Error: []byte("no head"), ErrorClass: coreStructs.ErrorClassWorker},
+			wantBackoff: true,
+		},
+		{
+			name:        "head error",
+			tr:          headTransport{err: errors.New("connection refused")},
+			want:        structures.LagRecord{TaskID: "t", Error: []byte("connection refused"), ErrorClass: coreStructs.ErrorClassWorker},
+			wantBackoff: true,
+			wantErr:     true,
+		},
+		{
+			name:        "status error",
+			tr:          headTransport{hr: structures.HeadResponse{Height: 120}},
+			sg:          statusGetter{err: errors.New("database down")},
+			want:        structures.LagRecord{TaskID: "t", Head: 120, Error: []byte("error getting indexed height: database down"), ErrorClass: coreStructs.ErrorClassWorker},
+			wantBackoff: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &memDriver{}
+			c := NewClient(zap.NewNop(), persistence.NewChainLagStorageTransport(d), auth.AuthCredentials{}, staticTargets{}, &tt.sg)
+			c.AddTransport("test", tt.tr)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			backoff, err := c.Run(ctx, coreStructs.RunConfigParams{Network: "n", ChainID: "c", Version: "0.0.1", Kind: RunnerName, TaskID: "t", Config: tt.config})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if backoff != tt.wantBackoff {
+				t.Errorf("backoff = %t, want %t", backoff, tt.wantBackoff)
+			}
+
+			if len(d.lags) != 1 {
+				t.Fatalf("stored %d measurements, want 1", len(d.lags))
+			}
+			got := d.lags[0]
+			if got.TaskID != tt.want.TaskID || got.Head != tt.want.Head || got.Height != tt.want.Height || got.Lag != tt.want.Lag {
+				t.Errorf("stored %+v, want %+v", got, tt.want)
+			}
+			if string(got.Error) != string(tt.want.Error) || got.ErrorClass != tt.want.ErrorClass {
+				t.Errorf("stored error (%q, %q), want (%q, %q)", got.Error, got.ErrorClass, tt.want.Error, tt.want.ErrorClass)
+			}
+			if tt.sg.taskID != "" && tt.sg.taskID != tt.want.TaskID {
+				t.Errorf("asked status of task %q, want %q", tt.sg.taskID, tt.want.TaskID)
+			}
+		})
+	}
+}
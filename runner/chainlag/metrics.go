@@ -0,0 +1,19 @@
+package chainlag
+
+import "github.com/figment-networks/indexing-engine/metrics"
+
+var lagGauge = metrics.MustNewGaugeWithTags(metrics.Options{
+	Namespace: "scheduler",
+	Subsystem: "chainlag",
+	Name:      "lag",
+	Desc:      "Number of blocks the indexed height is behind the chain head",
+	Tags:      []string{"network", "chain_id", "version", "task_id"},
+})
+
+var headGauge = metrics.MustNewGaugeWithTags(metrics.Options{
+	Namespace: "scheduler",
+	Subsystem: "chainlag",
+	Name:      "head",
+	Desc:      "The latest known chain head",
+	Tags:      []string{"network", "chain_id", "version"},
+})
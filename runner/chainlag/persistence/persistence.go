@@ -0,0 +1,41 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+)
+
+type PDriver interface {
+	SetLag(ctx context.Context, rcp coreStructs.RunConfigParams, lRec structures.LagRecord) error
+	GetLags(ctx context.Context, network, chainID string) (lRecs []structures.LagRecord, err error)
+	GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRecs []structures.LagRecord, err error)
+	Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error
+}
+
+type ChainLagStorageTransport struct {
+	Driver PDriver
+}
+
+func NewChainLagStorageTransport(driver PDriver) *ChainLagStorageTransport {
+	return &ChainLagStorageTransport{
+		Driver: driver,
+	}
+}
+
+func (s *ChainLagStorageTransport) SetLag(ctx context.Context, rcp coreStructs.RunConfigParams, lRec structures.LagRecord) error {
+	return s.Driver.SetLag(ctx, rcp, lRec)
+}
+
+func (s *ChainLagStorageTransport) GetLags(ctx context.Context, network, chainID string) (lRecs []structures.LagRecord, err error) {
+	return s.Driver.GetLags(ctx, network, chainID)
+}
+
+func (s *ChainLagStorageTransport) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRecs []structures.LagRecord, err error) {
+	return s.Driver.GetRuns(ctx, kind, network, chainID, taskID, limit, offset)
+}
+
+func (s *ChainLagStorageTransport) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return s.Driver.Purge(ctx, rcp)
+}
@@ -0,0 +1,125 @@
+package postgresstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+)
+
+type Driver struct {
+	db *sql.DB
+}
+
+func NewDriver(db *sql.DB) *Driver {
+	return &Driver{
+		db: db,
+	}
+}
+
+func (d *Driver) SetLag(ctx context.Context, rcp coreStructs.RunConfigParams, lRec structures.LagRecord) (err error) {
+	_, err = d.db.ExecContext(ctx, "INSERT INTO schedule_chainlag (network, chain_id, version, kind, task_id, head, height, lag, error, error_class) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)",
+		rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, lRec.TaskID, lRec.Head, lRec.Height, lRec.Lag, lRec.Error, lRec.ErrorClass)
+	return err
+}
+
+// GetLags returns the latest successful measurement of every task, optionally filtered by network and chain
+func (d *Driver) GetLags(ctx context.Context, network, chainID string) (lRecs []structures.LagRecord, err error) {
+	q := "SELECT DISTINCT ON (network, chain_id, version, task_id) network, chain_id, version, task_id, time, head, height, lag, error, error_class FROM schedule_chainlag WHERE (error IS NULL OR error = '')"
+
+	var args []interface{}
+	if network != "" {
+		args = append(args, network)
+		q += ` AND network = $` + strconv.Itoa(len(args))
+	}
+	if chainID != "" {
+		args = append(args, chainID)
+		q += ` AND chain_id = $` + strconv.Itoa(len(args))
+	}
+	q += " ORDER BY network, chain_id, version, task_id, time DESC"
+
+	rows, err := d.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		lRec := structures.LagRecord{}
+		if err := rows.Scan(&lRec.Network, &lRec.ChainID, &lRec.Version, &lRec.TaskID, &lRec.Time, &lRec.Head, &lRec.Height, &lRec.Lag, &lRec.Error, &lRec.ErrorClass); err != nil {
+			return nil, err
+		}
+		lRecs = append(lRecs, lRec)
+	}
+
+	return lRecs, rows.Err()
+}
+
+func (d *Driver) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) (err error) {
+	_, err = d.db.ExecContext(ctx, "DELETE FROM schedule_chainlag WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+	return err
+}
+
+func (d *Driver) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRecs []structures.LagRecord, err error) {
+	q := "SELECT network, chain_id, version, task_id, time, head, height, lag, error, error_class FROM schedule_chainlag "
+
+	var (
+		args   []interface{}
+		wherec []string
+		i      = 1
+	)
+
+	if network != "" {
+		wherec = append(wherec, ` network =  $`+strconv.Itoa(i))
+		args = append(args, network)
+		i++
+	}
+	if kind != "" {
+		wherec = append(wherec, ` kind =  $`+strconv.Itoa(i))
+		args = append(args, kind)
+		i++
+	}
+	if taskID != "" {
+		wherec = append(wherec, ` task_id =  $`+strconv.Itoa(i))
+		args = append(args, taskID)
+		i++
+	}
+	if chainID != "" {
+		wherec = append(wherec, ` chain_id =  $`+strconv.Itoa(i))
+		args = append(args, chainID)
+		i++
+	}
+	if len(args) > 0 {
+		q += ` WHERE `
+		q += strings.Join(wherec, " AND ")
+	}
+
+	q += ` ORDER BY time DESC LIMIT $` + strconv.Itoa(i)
+	args = append(args, limit)
+	i++
+
+	if offset > 0 {
+		q += ` OFFSET $` + strconv.Itoa(i)
+		args = append(args, offset)
+	}
+
+	rows, err := d.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		lRec := structures.LagRecord{}
+		if err := rows.Scan(&lRec.Network, &lRec.ChainID, &lRec.Version, &lRec.TaskID, &lRec.Time, &lRec.Head, &lRec.Height, &lRec.Lag, &lRec.Error, &lRec.ErrorClass); err != nil {
+			return nil, err
+		}
+		lRecs = append(lRecs, lRec)
+	}
+
+	return lRecs, rows.Err()
+}
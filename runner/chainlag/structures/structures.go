@@ -0,0 +1,60 @@
+package structures
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// LagRecord is the chain head compared to the indexed height of lastdata task
+type LagRecord struct {
+	Network string    `json:"network"`
+	ChainID string    `json:"chain_id"`
+	Version string    `json:"version"`
+	TaskID  string    `json:"task_id"`
+	Time    time.Time `json:"time"`
+
+	Head   uint64 `json:"head"`
+	Height uint64 `json:"height"`
+	Lag    uint64 `json:"lag"`
+
+	Error      []byte `json:"error"`
+	ErrorClass string `json:"error_class"`
+}
+
+type HeadRequest struct {
+	Network string `json:"network"`
+	ChainID string `json:"chain_id"`
+	Version string `json:"version"`
+	TaskID  string `json:"task_id"`
+
+	// Method or Endpoint override the default ones of transport
+	Method   string `json:"-"`
+	Endpoint string `json:"-"`
+}
+
+type HeadResponse struct {
+	Height uint64 `json:"height"`
+	Error  []byte `json:"error"`
+}
+
+// Config is the runner configuration, taken from schedule config. All the fields are optional.
+type Config struct {
+	// IndexedTaskID is the task id of lastdata task, defaults to the task id of schedule
+	IndexedTaskID string `json:"indexed_task_id"`
+
+	// Address and ConnType point to the node asked for the chain head, instead of the worker
+	Address  string `json:"address"`
+	ConnType string `json:"conn_type"`
+
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+}
+
+func ConfigFromMapInterface(a map[string]interface{}) (cfg Config, err error) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(b, &cfg)
+	return cfg, err
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+const ConnectionTypeHTTP = "http"
+
+// DefaultEndpoint is the endpoint of chain head, if not set in schedule config
+const DefaultEndpoint = "/chain_head"
+
+type ChainLagHTTPTransport struct {
+	client *http.Client
+	l      *zap.Logger
+}
+
+func NewChainLagHTTPTransport(l *zap.Logger) *ChainLagHTTPTransport {
+	return &ChainLagHTTPTransport{
+		l: l,
+		client: &http.Client{
+			Timeout: time.Second * 40,
+		},
+	}
+}
+
+func (cl ChainLagHTTPTransport) GetHead(ctx context.Context, t coreStructs.Target, hReq structures.HeadRequest) (hr structures.HeadResponse, err error) {
+	endpoint := hReq.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	if err := enc.Encode(&hReq); err != nil {
+		return hr, &coreStructs.RunError{Contents: fmt.Errorf("error encoding request: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.Address+endpoint, b)
+	if err != nil {
+		return hr, &coreStructs.RunError{Contents: fmt.Errorf("error creating request: %w", err)}
+	}
+
+	resp, err := cl.client.Do(req)
+	if err != nil {
+		return hr, &coreStructs.RunError{Contents: fmt.Errorf("error getting response:  %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return hr, &coreStructs.RunError{Contents: fmt.Errorf("error response: %s", resp.Status)}
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	if err = dec.Decode(&hr); err != nil {
+		return hr, &coreStructs.RunError{Contents: fmt.Errorf("error decoding response:  %w", err)}
+	}
+
+	return hr, nil
+}
@@ -0,0 +1,81 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/conn"
+	"github.com/figment-networks/indexer-scheduler/conn/tray"
+	"github.com/figment-networks/indexer-scheduler/runner/chainlag/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const ConnectionTypeWS = "ws"
+
+// DefaultMethod is the json-rpc method of chain head, if not set in schedule config
+const DefaultMethod = "chain_head"
+
+type ChainLagWSTransport struct {
+	l      *zap.Logger
+	ct     *tray.ConnTray
+	nextID uint64
+}
+
+func NewChainLagWSTransport(l *zap.Logger, ct *tray.ConnTray) *ChainLagWSTransport {
+	return &ChainLagWSTransport{
+		l:  l,
+		ct: ct,
+	}
+}
+
+func (cl *ChainLagWSTransport) GetHead(ctx context.Context, t coreStructs.Target, hReq structures.HeadRequest) (hr structures.HeadResponse, err error) {
+	method := hReq.Method
+	if method == "" {
+		method = DefaultMethod
+	}
+
+	rpc, err := cl.ct.Get(ConnectionTypeWS, t.Address)
+	if err != nil {
+		return hr, &coreStructs.RunError{Contents: fmt.Errorf("error getting connection:  %w", err)}
+	}
+
+	sID := uuid.New()
+	ch := make(chan conn.Response, 1)
+	defer rpc.CloseStream(sID.String())
+	defer close(ch)
+
+	sent := atomic.AddUint64(&cl.nextID, 1)
+	if err := rpc.Send(sID.String(), ch, sent, method, []interface{}{hReq}); err != nil {
+		return hr, &coreStructs.RunError{Contents: fmt.Errorf("error sending request:  %w", err)}
+	}
+
+	for {
+		select {
+		case resp := <-ch:
+			if resp.ID != sent {
+				cl.l.Warn("Outstanding message passed", zap.Any("response", resp))
+				continue
+			}
+			if resp.Error != nil {
+				return hr, &coreStructs.RunError{Contents: fmt.Errorf("error getting response:  %w", resp.Error)}
+			}
+			if len(resp.Result) != 0 {
+				dec := json.NewDecoder(bytes.NewReader(resp.Result))
+				if err = dec.Decode(&hr); err != nil {
+					return hr, &coreStructs.RunError{Contents: fmt.Errorf("error decoding response:  %w", err)}
+				}
+			}
+			return hr, nil
+		case <-ctx.Done():
+			return hr, &coreStructs.RunError{Contents: fmt.Errorf("error getting response: %w", ctx.Err())}
+		case <-time.After(time.Minute * 5):
+			return hr, &coreStructs.RunError{Contents: fmt.Errorf("error getting response timed out")}
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package gapdetect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/runner/gapdetect/persistence"
+	"github.com/figment-networks/indexer-scheduler/runner/gapdetect/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+const RunnerName = "gapdetect"
+
+// IndexedKind is the kind of tasks, which history is analyzed
+const IndexedKind = "lastdata"
+
+// BackfillKind is the kind of schedules created to backfill found gaps
+const BackfillKind = "syncrange"
+
+// batchLimit is the maximum number of history records analyzed in a single run
+const batchLimit = 1000
+
+const defaultBackfillInterval = 10 * time.Second
+
+// HistoryGetter returns the runs of indexing task stored after given time
+type HistoryGetter interface {
+	History(ctx context.Context, rcp coreStructs.RunConfigParams, since time.Time, limit uint64) ([]coreStructs.LatestStatus, error)
+}
+
+// ScheduleAdder stores new schedule, it is picked up by the next load of schedules
+type ScheduleAdder interface {
+	AddConfig(ctx context.Context, rc coreStructs.RunConfig) (err error)
+}
+
+// Client finds missing and regressed ranges of heights in the history of lastdata tasks
+type Client struct {
+	store   *persistence.GapStorageTransport
+	history HistoryGetter
+	adder   ScheduleAdder
+	logger  *zap.Logger
+	creds   auth.AuthCredentials
+}
+
+func NewClient(logger *zap.Logger, store *persistence.GapStorageTransport, ac auth.AuthCredentials, history HistoryGetter, adder ScheduleAdder) *Client {
+	return &Client{
+		store:   store,
+		history: history,
+		adder:   adder,
+		logger:  logger,
+		creds:   ac,
+	}
+}
+
+func (c *Client) Name() string {
+	return RunnerName
+}
+
+func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (backoff bool, err error) {
+	cfg, err := structures.ConfigFromMapInterface(rcp.Config)
+	if err != nil {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error in config [%s]: %w", RunnerName, err), Unrecoverable: true}
+	}
+
+	backfillInterval := defaultBackfillInterval
+	if cfg.BackfillInterval != "" {
+		if backfillInterval, err = time.ParseDuration(cfg.BackfillInterval); err != nil {
+			return false, &coreStructs.RunError{Contents: fmt.Errorf("error in config backfill_interval [%s]: %w", RunnerName, err), Unrecoverable: true}
+		}
+	}
+
+	indexedParams := coreStructs.RunConfigParams{Network: rcp.Network, ChainID: rcp.ChainID, Version: rcp.Version, Kind: IndexedKind, TaskID: rcp.TaskID}
+	if cfg.IndexedTaskID != "" {
+		indexedParams.TaskID = cfg.IndexedTaskID
+	}
+
+	scan, err := c.store.GetLatestScan(ctx, rcp)
+	if err != nil && !errors.Is(err, params.ErrNotFound) {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error getting data from store GetLatestScan [%s]:  %w", RunnerName, err)}
+	}
+
+	statuses, err := c.history.History(ctx, indexedParams, scan.ScannedUntil, batchLimit)
+	if err != nil {
+		return true, &coreStructs.RunError{Contents: fmt.Errorf("error getting history [%s]:  %w", RunnerName, err)}
+	}
+
+	newScan := structures.Scan{ScannedUntil: scan.ScannedUntil, LastHeight: scan.LastHeight}
+	prev := scan.LastHeight
+	for _, s := range statuses {
+		newScan.ScannedUntil = s.Time
+		// failed runs keep the previous height, so they say nothing about gaps
+		if !s.Succeeded || s.Height == 0 {
+			continue
+		}
+
+		var gap *structures.Gap
+		switch {
+		case prev == 0:
+		case s.Height < prev:
+			gap = &structures.Gap{Kind: structures.GapRegressed, HeightFrom: s.Height + 1, HeightTo: prev}
+		case cfg.MaxStep > 0 && s.Height-prev > cfg.MaxStep:
+			gap = &structures.Gap{Kind: structures.GapMissing, HeightFrom: prev + 1, HeightTo: s.Height}
+		}
+		prev = s.Height
+
+		if gap == nil {
+			continue
+		}
+		gap.Network, gap.ChainID, gap.Version, gap.TaskID = rcp.Network, rcp.ChainID, rcp.Version, indexedParams.TaskID
+		if err = c.addGap(ctx, *gap, cfg.Backfill, backfillInterval); err != nil {
+			break
+		}
+		newScan.Gaps++
+	}
+	newScan.LastHeight = prev
+
+	if err != nil {
+		newScan.Error = []byte(err.Error())
+	}
+
+	c.logger.Debug("[GapDetect] Scanned",
+		zap.String("network", rcp.Network),
+		zap.String("chain_id", rcp.ChainID),
+		zap.String("task_id", indexedParams.TaskID),
+		zap.Int("records", len(statuses)),
+		zap.Uint64("last_height", newScan.LastHeight),
+		zap.Uint64("gaps", newScan.Gaps),
+		zap.String("error", string(newScan.Error)),
+	)
+
+	// failed scan is stored, but the next one starts from the same point
+	if err != nil {
+		newScan.ScannedUntil = scan.ScannedUntil
+		newScan.LastHeight = scan.LastHeight
+	}
+
+	sCtx, sCancel := coreStructs.StoreContext()
+	defer sCancel()
+	if err2 := c.store.SetScan(sCtx, rcp, newScan); err2 != nil {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error writing scan SetScan [%s]:  %w", RunnerName, err2)}
+	}
+
+	if err != nil {
+		return true, &coreStructs.RunError{Contents: fmt.Errorf("error storing gap [%s]:  %w", RunnerName, err)}
+	}
+
+	if len(statuses) == batchLimit {
+		return false, coreStructs.ErrBehind
+	}
+
+	return false, nil
+}
+
+// addGap stores the gap and creates the backfill schedule for it, if the stored gap has none yet.
+// Backfill is decided on the stored gap rather than on the insert, so a failure after storing the gap is retried by the next scan
+func (c *Client) addGap(ctx context.Context, gap structures.Gap, backfill bool, interval time.Duration) error {
+	added, backfillTaskID, err := c.store.AddGap(ctx, gap)
+	if err != nil {
+		return err
+	}
+
+	if added {
+		c.logger.Info("[GapDetect] Found gap",
+			zap.String("network", gap.Network),
+			zap.String("chain_id", gap.ChainID),
+			zap.String("task_id", gap.TaskID),
+			zap.String("kind", string(gap.Kind)),
+			zap.Uint64("height_from", gap.HeightFrom),
+			zap.Uint64("height_to", gap.HeightTo),
+		)
+	}
+
+	if !backfill || backfillTaskID != "" {
+		return nil
+	}
+
+	gap.BackfillTaskID = fmt.Sprintf("%s-backfill-%d-%d", gap.TaskID, gap.HeightFrom, gap.HeightTo)
+	// schedule is added directly to the store, it is started by the next load of schedules
+	err = c.adder.AddConfig(ctx, coreStructs.RunConfig{
+		Network:  gap.Network,
+		ChainID:  gap.ChainID,
+		Version:  gap.Version,
+		TaskID:   gap.BackfillTaskID,
+		Duration: interval,
+		Kind:     BackfillKind,
+		Enabled:  true,
+		Status:   coreStructs.StateAdded,
+		Config: map[string]interface{}{
+			"height_from": strconv.FormatUint(gap.HeightFrom, 10),
+			"height_to":   strconv.FormatUint(gap.HeightTo, 10),
+		},
+		Labels: map[string]string{"backfill_of": gap.TaskID},
+	})
+	if err != nil && !errors.Is(err, params.ErrAlreadyRegistred) {
+		return fmt.Errorf("error adding backfill schedule: %w", err)
+	}
+
+	return c.store.SetGapBackfill(ctx, gap)
+}
+
+// PurgeHistory removes the state of analysis of given task
+func (c *Client) PurgeHistory(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return c.store.Purge(ctx, rcp)
+}
+
+func (c *Client) RegisterHandles(mux *http.ServeMux) {
+	mux.HandleFunc("/scheduler/runner/gapdetect/report", c.handlerReport)
+}
+
+// handlerReport lists the found gaps and created backfills, optionally filtered by `network`, `chain_id` and `task_id` query parameters
+func (c *Client) handlerReport(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	q := r.URL.Query()
+	gaps, err := c.store.GetGaps(r.Context(), q.Get("network"), q.Get("chain_id"), q.Get("task_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	if gaps == nil {
+		gaps = []structures.Gap{}
+	}
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(gaps)
+}
@@ -0,0 +1,228 @@
+package gapdetect
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/runner/gapdetect/persistence"
+	"github.com/figment-networks/indexer-scheduler/runner/gapdetect/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+type memDriver struct {
+	scans []structures.Scan
+	gaps  []structures.Gap
+}
+
+func (d *memDriver) GetLatestScan(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.Scan, error) {
+	if len(d.scans) == 0 {
+		return structures.Scan{}, params.ErrNotFound
+	}
+	return d.scans[len(d.scans)-1], nil
+}
+
+func (d *memDriver) SetScan(ctx context.Context, rcp coreStructs.RunConfigParams, scan structures.Scan) error {
+	d.scans = append(d.scans, scan)
+	return nil
+}
+
+func (d *memDriver) AddGap(ctx context.Context, gap structures.Gap) (added bool, backfillTaskID string, err error) {
+	for _, g := range d.gaps {
+		if g.TaskID == gap.TaskID && g.Kind == gap.Kind && g.HeightFrom == gap.HeightFrom && g.HeightTo == gap.HeightTo {
+			return false, g.BackfillTaskID, nil
+		}
+	}
+	d.gaps = append(d.gaps, gap)
+	return true, "", nil
+}
+
+func (d *memDriver) SetGapBackfill(ctx context.Context, gap structures.Gap) error {
+	for i, g := range d.gaps {
+		if g.TaskID == gap.TaskID && g.Kind == gap.Kind && g.HeightFrom == gap.HeightFrom && g.HeightTo == gap.HeightTo {
+			d.gaps[i].BackfillTaskID = gap.BackfillTaskID
+		}
+	}
+	return nil
+}
+
+func (d *memDriver) GetGaps(ctx context.Context, network, chainID, taskID string) ([]structures.Gap, error) {
+	return d.gaps, nil
+}
+
+func (d *memDriver) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return nil
+}
+
+// memHistory returns the records stored after given time, like the lastdata store
+type memHistory []coreStructs.LatestStatus
+
+func (h memHistory) History(ctx context.Context, rcp coreStructs.RunConfigParams, since time.Time, limit uint64) (statuses []coreStructs.LatestStatus, err error) {
+	for _, s := range h {
+		if !s.Time.After(since) {
+			continue
+		}
+		if uint64(len(statuses)) == limit {
+			break
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+type memAdder struct {
+	err     error
+	configs []coreStructs.RunConfig
+}
+
+func (a *memAdder) AddConfig(ctx context.Context, rc coreStructs.RunConfig) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.configs = append(a.configs, rc)
+	return nil
+}
+
+// history builds succeeded records of given heights, a second apart
+func history(heights ...uint64) (h memHistory) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, height := range heights {
+		h = append(h, coreStructs.LatestStatus{Height: height, Time: start.Add(time.Duration(i+1) * time.Second), Succeeded: true})
+	}
+	return h
+}
+
+func testParams(config map[string]interface{}) coreStructs.RunConfigParams {
+	return coreStructs.RunConfigParams{Network: "n", ChainID: "c", Version: "0.0.1", Kind: RunnerName, TaskID: "t", Config: config}
+}
+
+func TestRunDetectsGaps(t *testing.T) {
+	failed := history(10, 20, 5, 30)
+	failed[2].Succeeded = false
+
+	tests := []struct {
+		name     string
+		history  memHistory
+		maxStep  float64
+		wantGaps []structures.Gap
+	}{
+		{
+			name:    "steady progress",
+			history: history(10, 20, 30),
+			maxStep: 10,
+		},
+		{
+			name:     "regression",
+			history:  history(10, 20, 15, 25),
+			wantGaps: []structures.Gap{{Kind: structures.GapRegressed, HeightFrom: 16, HeightTo: 20}},
+		},
+		{
+			name:     "jump above max_step",
+			history:  history(10, 20, 45),
+			maxStep:  10,
+			wantGaps: []structures.Gap{{Kind: structures.GapMissing, HeightFrom: 21, HeightTo: 45}},
+		},
+		{
+			name:    "jump without max_step",
+			history: history(10, 20, 45),
+		},
+		{
+			name:    "failed records skipped",
+			history: failed,
+			maxStep: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &memDriver{}
+			c := NewClient(zap.NewNop(), persistence.NewGapStorageTransport(d), auth.AuthCredentials{}, tt.history, &memAdder{})
+
+			if _, err := c.Run(context.Background(), testParams(map[string]interface{}{"max_step": tt.maxStep})); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for i := range tt.wantGaps {
+				tt.wantGaps[i].Network, tt.wantGaps[i].ChainID, tt.wantGaps[i].Version, tt.wantGaps[i].TaskID = "n", "c", "0.0.1", "t"
+			}
+			if !reflect.DeepEqual(d.gaps, tt.wantGaps) {
+				t.Errorf("gaps = %+v, want %+v", d.gaps, tt.wantGaps)
+			}
+
+			scan := d.scans[len(d.scans)-1]
+			if last := tt.history[len(tt.history)-1]; !scan.ScannedUntil.Equal(last.Time) {
+				t.Errorf("scanned until %s, want %s", scan.ScannedUntil, last.Time)
+			}
+			if scan.Gaps != uint64(len(tt.wantGaps)) {
+				t.Errorf("scan gaps = %d, want %d", scan.Gaps, len(tt.wantGaps))
+			}
+		})
+	}
+}
+
+func TestRunBatchBoundary(t *testing.T) {
+	heights := make([]uint64, batchLimit+2)
+	for i := range heights {
+		heights[i] = uint64(i+1) * 10
+	}
+	// the jump lands right after the first batch, so it is found only if the second run continues from the stored height
+	heights[batchLimit] = heights[batchLimit-1] + 100
+	heights[batchLimit+1] = heights[batchLimit] + 10
+
+	d := &memDriver{}
+	c := NewClient(zap.NewNop(), persistence.NewGapStorageTransport(d), auth.AuthCredentials{}, history(heights...), &memAdder{})
+	rcp := testParams(map[string]interface{}{"max_step": 50})
+
+	if _, err := c.Run(context.Background(), rcp); !errors.Is(err, coreStructs.ErrBehind) {
+		t.Fatalf("first run error = %v, want ErrBehind", err)
+	}
+	if len(d.gaps) != 0 {
+		t.Fatalf("gaps after first batch = %+v, want none", d.gaps)
+	}
+	if d.scans[0].LastHeight != heights[batchLimit-1] {
+		t.Fatalf("last height = %d, want %d", d.scans[0].LastHeight, heights[batchLimit-1])
+	}
+
+	if _, err := c.Run(context.Background(), rcp); err != nil {
+		t.Fatalf("second run error = %v, want nil", err)
+	}
+	if len(d.gaps) != 1 || d.gaps[0].HeightFrom != heights[batchLimit-1]+1 || d.gaps[0].HeightTo != heights[batchLimit] {
+		t.Errorf("gaps after second batch = %+v, want [%d, %d]", d.gaps, heights[batchLimit-1]+1, heights[batchLimit])
+	}
+}
+
+func TestRunRetriesBackfill(t *testing.T) {
+	d := &memDriver{}
+	adder := &memAdder{err: errors.New("store unavailable")}
+	c := NewClient(zap.NewNop(), persistence.NewGapStorageTransport(d), auth.AuthCredentials{}, history(10, 20, 15), adder)
+	rcp := testParams(map[string]interface{}{"backfill": true})
+
+	if _, err := c.Run(context.Background(), rcp); err == nil {
+		t.Fatal("expected error of failed backfill")
+	}
+	if len(d.gaps) != 1 || d.gaps[0].BackfillTaskID != "" {
+		t.Fatalf("gaps = %+v, want one stored without backfill", d.gaps)
+	}
+
+	adder.err = nil
+	if _, err := c.Run(context.Background(), rcp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(adder.configs) != 1 || d.gaps[0].BackfillTaskID != adder.configs[0].TaskID {
+		t.Fatalf("backfills = %+v, gaps = %+v, want backfill of the stored gap", adder.configs, d.gaps)
+	}
+
+	// gap with backfill is not backfilled again
+	d.scans = nil
+	if _, err := c.Run(context.Background(), rcp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(adder.configs) != 1 {
+		t.Errorf("backfills = %d, want 1", len(adder.configs))
+	}
+}
@@ -0,0 +1,51 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/figment-networks/indexer-scheduler/runner/gapdetect/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+)
+
+type PDriver interface {
+	GetLatestScan(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.Scan, error)
+	SetScan(ctx context.Context, rcp coreStructs.RunConfigParams, scan structures.Scan) error
+	AddGap(ctx context.Context, gap structures.Gap) (added bool, backfillTaskID string, err error)
+	SetGapBackfill(ctx context.Context, gap structures.Gap) error
+	GetGaps(ctx context.Context, network, chainID, taskID string) ([]structures.Gap, error)
+	Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error
+}
+
+type GapStorageTransport struct {
+	Driver PDriver
+}
+
+func NewGapStorageTransport(driver PDriver) *GapStorageTransport {
+	return &GapStorageTransport{
+		Driver: driver,
+	}
+}
+
+func (s *GapStorageTransport) GetLatestScan(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.Scan, error) {
+	return s.Driver.GetLatestScan(ctx, rcp)
+}
+
+func (s *GapStorageTransport) SetScan(ctx context.Context, rcp coreStructs.RunConfigParams, scan structures.Scan) error {
+	return s.Driver.SetScan(ctx, rcp, scan)
+}
+
+func (s *GapStorageTransport) AddGap(ctx context.Context, gap structures.Gap) (added bool, backfillTaskID string, err error) {
+	return s.Driver.AddGap(ctx, gap)
+}
+
+func (s *GapStorageTransport) SetGapBackfill(ctx context.Context, gap structures.Gap) error {
+	return s.Driver.SetGapBackfill(ctx, gap)
+}
+
+func (s *GapStorageTransport) GetGaps(ctx context.Context, network, chainID, taskID string) ([]structures.Gap, error) {
+	return s.Driver.GetGaps(ctx, network, chainID, taskID)
+}
+
+func (s *GapStorageTransport) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return s.Driver.Purge(ctx, rcp)
+}
@@ -0,0 +1,98 @@
+package postgresstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/runner/gapdetect/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+)
+
+type Driver struct {
+	db *sql.DB
+}
+
+func NewDriver(db *sql.DB) *Driver {
+	return &Driver{
+		db: db,
+	}
+}
+
+func (d *Driver) GetLatestScan(ctx context.Context, rcp coreStructs.RunConfigParams) (scan structures.Scan, err error) {
+	scannedUntil := sql.NullTime{}
+	row := d.db.QueryRowContext(ctx, "SELECT time, scanned_until, last_height, gaps, error FROM schedule_gap_scan WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5 ORDER BY time DESC LIMIT 1", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+	if err := row.Scan(&scan.Time, &scannedUntil, &scan.LastHeight, &scan.Gaps, &scan.Error); err != nil {
+		if err == sql.ErrNoRows {
+			return scan, params.ErrNotFound
+		}
+		return scan, err
+	}
+	scan.ScannedUntil = scannedUntil.Time
+	return scan, nil
+}
+
+func (d *Driver) SetScan(ctx context.Context, rcp coreStructs.RunConfigParams, scan structures.Scan) error {
+	_, err := d.db.ExecContext(ctx, "INSERT INTO schedule_gap_scan (network, chain_id, version, kind, task_id, scanned_until, last_height, gaps, error) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)",
+		rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID, sql.NullTime{Time: scan.ScannedUntil, Valid: !scan.ScannedUntil.IsZero()}, scan.LastHeight, scan.Gaps, scan.Error)
+	return err
+}
+
+// AddGap stores the gap, unless the same one was already found. It returns the backfill already created for the stored gap
+func (d *Driver) AddGap(ctx context.Context, gap structures.Gap) (added bool, backfillTaskID string, err error) {
+	row := d.db.QueryRowContext(ctx, `INSERT INTO schedule_gap (network, chain_id, version, task_id, kind, height_from, height_to) VALUES ($1,$2,$3,$4,$5,$6,$7)
+		ON CONFLICT (network, chain_id, version, task_id, kind, height_from, height_to) DO UPDATE SET backfill_task_id = schedule_gap.backfill_task_id
+		RETURNING (xmax = 0), backfill_task_id`,
+		gap.Network, gap.ChainID, gap.Version, gap.TaskID, gap.Kind, gap.HeightFrom, gap.HeightTo)
+	err = row.Scan(&added, &backfillTaskID)
+	return added, backfillTaskID, err
+}
+
+func (d *Driver) SetGapBackfill(ctx context.Context, gap structures.Gap) error {
+	_, err := d.db.ExecContext(ctx, "UPDATE schedule_gap SET backfill_task_id = $1 WHERE network = $2 AND chain_id = $3 AND version = $4 AND task_id = $5 AND kind = $6 AND height_from = $7 AND height_to = $8",
+		gap.BackfillTaskID, gap.Network, gap.ChainID, gap.Version, gap.TaskID, gap.Kind, gap.HeightFrom, gap.HeightTo)
+	return err
+}
+
+func (d *Driver) GetGaps(ctx context.Context, network, chainID, taskID string) (gaps []structures.Gap, err error) {
+	q := "SELECT network, chain_id, version, task_id, kind, height_from, height_to, detected_at, backfill_task_id FROM schedule_gap WHERE true"
+
+	var args []interface{}
+	if network != "" {
+		args = append(args, network)
+		q += ` AND network = $` + strconv.Itoa(len(args))
+	}
+	if chainID != "" {
+		args = append(args, chainID)
+		q += ` AND chain_id = $` + strconv.Itoa(len(args))
+	}
+	if taskID != "" {
+		args = append(args, taskID)
+		q += ` AND task_id = $` + strconv.Itoa(len(args))
+	}
+	q += " ORDER BY network, chain_id, version, task_id, height_from"
+
+	rows, err := d.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		gap := structures.Gap{}
+		if err := rows.Scan(&gap.Network, &gap.ChainID, &gap.Version, &gap.TaskID, &gap.Kind, &gap.HeightFrom, &gap.HeightTo, &gap.DetectedAt, &gap.BackfillTaskID); err != nil {
+			return nil, err
+		}
+		gaps = append(gaps, gap)
+	}
+
+	return gaps, rows.Err()
+}
+
+// Purge removes the state of analysis, so the history is analyzed again from the start. Found gaps are kept.
+func (d *Driver) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	_, err := d.db.ExecContext(ctx, "DELETE FROM schedule_gap_scan WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+	return err
+}
@@ -0,0 +1,63 @@
+package structures
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GapKind string
+
+var (
+	// GapMissing is the range skipped by height jump greater than allowed step
+	GapMissing GapKind = "missing"
+	// GapRegressed is the range reported again after the height went back
+	GapRegressed GapKind = "regressed"
+)
+
+// Gap is a suspicious range of heights found in the history of lastdata task
+type Gap struct {
+	Network string `json:"network"`
+	ChainID string `json:"chain_id"`
+	Version string `json:"version"`
+	TaskID  string `json:"task_id"`
+
+	Kind       GapKind   `json:"kind"`
+	HeightFrom uint64    `json:"height_from"`
+	HeightTo   uint64    `json:"height_to"`
+	DetectedAt time.Time `json:"detected_at"`
+
+	// BackfillTaskID is the task id of syncrange schedule created to backfill the gap
+	BackfillTaskID string `json:"backfill_task_id,omitempty"`
+}
+
+// Scan is the state of analysis after a single run, the next one continues from it
+type Scan struct {
+	Time         time.Time `json:"time"`
+	ScannedUntil time.Time `json:"scanned_until"`
+	LastHeight   uint64    `json:"last_height"`
+	Gaps         uint64    `json:"gaps"`
+	Error        []byte    `json:"error"`
+}
+
+// Config is the runner configuration, taken from schedule config. All the fields are optional.
+type Config struct {
+	// IndexedTaskID is the task id of analyzed lastdata task, defaults to the task id of schedule
+	IndexedTaskID string `json:"indexed_task_id"`
+
+	// MaxStep is the greatest expected height change between two runs, zero disables detection of missing ranges
+	MaxStep uint64 `json:"max_step"`
+
+	// Backfill enables creating syncrange schedules for the found gaps
+	Backfill bool `json:"backfill"`
+	// BackfillInterval is the interval of created schedules, like `10s`
+	BackfillInterval string `json:"backfill_interval"`
+}
+
+func ConfigFromMapInterface(a map[string]interface{}) (cfg Config, err error) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(b, &cfg)
+	return cfg, err
+}
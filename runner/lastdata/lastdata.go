@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/figment-networks/indexer-scheduler/http/auth"
 	"github.com/figment-networks/indexer-scheduler/persistence/params"
@@ -51,6 +52,14 @@ func (c *Client) Name() string {
 	return RunnerName
 }
 
+// ResolveTargets chooses the target of the next run
+func (c *Client) ResolveTargets(ctx context.Context, rcp coreStructs.RunConfigParams) []coreStructs.Target {
+	if t, ok := c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID}); ok {
+		return []coreStructs.Target{t}
+	}
+	return nil
+}
+
 func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (backoff bool, err error) {
 	latest, err := c.store.GetLatest(ctx, rcp)
 	if err != nil && err != params.ErrNotFound {
@@ -65,7 +74,10 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 		RetryCount: latest.RetryCount,
 	}
 
-	t, ok := c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID})
+	t, ok := coreStructs.TargetFromContext(ctx, 0)
+	if !ok {
+		t, ok = c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID})
+	}
 	if !ok {
 		return false, &coreStructs.RunError{Contents: fmt.Errorf("error getting response:  %w", coreStructs.ErrNoDestinationAvailable)}
 	}
@@ -104,12 +116,14 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 	if len(resp.Error) != 0 {
 		lrec.Height = latest.Height
 		lrec.Error = resp.Error
+		lrec.ErrorClass = coreStructs.ErrorClassWorker
 		backoff = true
 		lrec.RetryCount++
 	}
 
 	if err != nil {
 		lrec.Error = []byte(err.Error())
+		lrec.ErrorClass = coreStructs.ClassifyError(ctx, err)
 		backoff = true
 		lrec.RetryCount++
 	}
@@ -124,7 +138,9 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 		zap.String("error", string(lrec.Error)),
 	)
 
-	if err2 := c.store.SetLatest(ctx, rcp, lrec); err2 != nil {
+	sCtx, sCancel := coreStructs.StoreContext()
+	defer sCancel()
+	if err2 := c.store.SetLatest(sCtx, rcp, lrec); err2 != nil {
 		return false, &coreStructs.RunError{Contents: fmt.Errorf("error writing last record SetLatest [%s]:  %w", RunnerName, err2)}
 	}
 
@@ -132,9 +148,51 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 		return backoff, &coreStructs.RunError{Contents: fmt.Errorf("error getting data from GetLastData [%s]:  %w", RunnerName, err)}
 	}
 
+	// height still advancing or the service knowing there is more to process means task may be behind, so it can be run again right away
+	if !backoff && !resp.Processing && (lrec.Height > latest.Height || resp.More) {
+		return false, coreStructs.ErrBehind
+	}
+
 	return backoff, nil
 }
 
 func (c *Client) RegisterHandles(mux *http.ServeMux) {
 	c.m.RegisterHandles(mux)
 }
+
+// LatestStatus returns the state of the latest stored run of the task
+func (c *Client) LatestStatus(ctx context.Context, rcp coreStructs.RunConfigParams) (coreStructs.LatestStatus, error) {
+	latest, err := c.store.GetLatest(ctx, rcp)
+	if err != nil {
+		return coreStructs.LatestStatus{}, err
+	}
+
+	return coreStructs.LatestStatus{
+		Height:    latest.Height,
+		Time:      latest.Time,
+		Succeeded: len(latest.Error) == 0,
+	}, nil
+}
+
+// History returns the state of runs of the task stored after since, in order of time
+func (c *Client) History(ctx context.Context, rcp coreStructs.RunConfigParams, since time.Time, limit uint64) ([]coreStructs.LatestStatus, error) {
+	recs, err := c.store.GetHeights(ctx, rcp, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]coreStructs.LatestStatus, len(recs))
+	for i, rec := range recs {
+		statuses[i] = coreStructs.LatestStatus{
+			Height:    rec.Height,
+			Time:      rec.Time,
+			Succeeded: len(rec.Error) == 0,
+		}
+	}
+	return statuses, nil
+}
+
+// PurgeHistory removes all the stored runs of given task
+func (c *Client) PurgeHistory(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return c.store.Purge(ctx, rcp)
+}
@@ -0,0 +1,85 @@
+package lastdata
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/persistence/params"
+	"github.com/figment-networks/indexer-scheduler/runner/lastdata/persistence"
+	"github.com/figment-networks/indexer-scheduler/runner/lastdata/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+type memDriver struct {
+	latest structures.LatestRecord
+	set    bool
+}
+
+func (d *memDriver) GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.LatestRecord, error) {
+	if !d.set {
+		return structures.LatestRecord{}, params.ErrNotFound
+	}
+	return d.latest, nil
+}
+
+func (d *memDriver) SetLatest(ctx context.Context, rcp coreStructs.RunConfigParams, latest structures.LatestRecord) error {
+	d.latest, d.set = latest, true
+	return nil
+}
+
+func (d *memDriver) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) ([]structures.LatestRecord, error) {
+	return nil, nil
+}
+
+func (d *memDriver) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return nil
+}
+
+func (d *memDriver) GetHeights(ctx context.Context, rcp coreStructs.RunConfigParams, since time.Time, limit uint64) ([]structures.LatestRecord, error) {
+	return nil, nil
+}
+
+type staticTargets struct{}
+
+func (staticTargets) Get(nv coreStructs.NVCKey) (coreStructs.Target, bool) {
+	return coreStructs.Target{Network: nv.Network, ChainID: nv.ChainID, Version: nv.Version, ConnType: "test"}, true
+}
+
+type advancingTransport struct {
+	step       uint64
+	more       bool
+	processing bool
+}
+
+func (tr advancingTransport) GetLastData(ctx context.Context, t coreStructs.Target, req structures.LatestDataRequest) (structures.LatestDataResponse, bool, error) {
+	return structures.LatestDataResponse{LastHeight: req.LastHeight + tr.step, More: tr.more, Processing: tr.processing}, tr.processing, nil
+}
+
+func TestRunBehind(t *testing.T) {
+	tests := []struct {
+		name       string
+		tr         advancingTransport
+		wantBehind bool
+	}{
+		{name: "not advancing is caught up", tr: advancingTransport{}},
+		{name: "advancing is behind", tr: advancingTransport{step: 10}, wantBehind: true},
+		{name: "more is behind", tr: advancingTransport{more: true}, wantBehind: true},
+		{name: "processing is never behind", tr: advancingTransport{step: 10, more: true, processing: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(zap.NewNop(), persistence.NewLastDataStorageTransport(&memDriver{}), auth.AuthCredentials{}, staticTargets{})
+			c.AddTransport("test", tt.tr)
+
+			_, err := c.Run(context.Background(), coreStructs.RunConfigParams{Network: "n", ChainID: "c", Version: "0.0.1", Kind: RunnerName, TaskID: "t"})
+			if behind := errors.Is(err, coreStructs.ErrBehind); behind != tt.wantBehind {
+				t.Errorf("behind = %t (err %v), want %t", behind, err, tt.wantBehind)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/figment-networks/indexer-scheduler/runner/lastdata/structures"
 	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
@@ -11,6 +12,8 @@ type PDriver interface {
 	GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.LatestRecord, error)
 	SetLatest(ctx context.Context, rcp coreStructs.RunConfigParams, latest structures.LatestRecord) error
 	GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRec []structures.LatestRecord, err error)
+	Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error
+	GetHeights(ctx context.Context, rcp coreStructs.RunConfigParams, since time.Time, limit uint64) (lRec []structures.LatestRecord, err error)
 }
 
 type LastDataStorageTransport struct {
@@ -34,3 +37,11 @@ func (s *LastDataStorageTransport) SetLatest(ctx context.Context, rcp coreStruct
 func (s *LastDataStorageTransport) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRec []structures.LatestRecord, err error) {
 	return s.Driver.GetRuns(ctx, kind, network, chainID, taskID, limit, offset)
 }
+
+func (s *LastDataStorageTransport) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return s.Driver.Purge(ctx, rcp)
+}
+
+func (s *LastDataStorageTransport) GetHeights(ctx context.Context, rcp coreStructs.RunConfigParams, since time.Time, limit uint64) (lRec []structures.LatestRecord, err error) {
+	return s.Driver.GetHeights(ctx, rcp, since, limit)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/figment-networks/indexer-scheduler/persistence/params"
 	"github.com/figment-networks/indexer-scheduler/runner/lastdata/structures"
@@ -23,9 +24,9 @@ func NewDriver(db *sql.DB) *Driver {
 }
 
 func (d *Driver) GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams) (lRec structures.LatestRecord, err error) {
-	row := d.db.QueryRowContext(ctx, "SELECT hash, height, latest_time, time,  nonce, retry, task_id FROM schedule_latest WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5  ORDER BY time DESC LIMIT 1", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+	row := d.db.QueryRowContext(ctx, "SELECT hash, height, latest_time, time,  nonce, retry, error, task_id FROM schedule_latest WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5  ORDER BY time DESC LIMIT 1", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
 	if row != nil {
-		if err := row.Scan(&lRec.Hash, &lRec.Height, &lRec.LastTime, &lRec.Time, &lRec.Nonce, &lRec.RetryCount, &lRec.TaskID); err != nil {
+		if err := row.Scan(&lRec.Hash, &lRec.Height, &lRec.LastTime, &lRec.Time, &lRec.Nonce, &lRec.RetryCount, &lRec.Error, &lRec.TaskID); err != nil {
 			if err == sql.ErrNoRows {
 				return lRec, params.ErrNotFound
 			}
@@ -36,13 +37,37 @@ func (d *Driver) GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams)
 }
 
 func (d *Driver) SetLatest(ctx context.Context, rcp coreStructs.RunConfigParams, lRec structures.LatestRecord) (err error) {
-	_, err = d.db.ExecContext(ctx, "INSERT INTO schedule_latest (latest_time, network, chain_id, version, kind, task_id, hash, height, nonce, retry, error) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)",
-		lRec.LastTime, rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID, lRec.Hash, lRec.Height, lRec.Nonce, lRec.RetryCount, lRec.Error)
+	_, err = d.db.ExecContext(ctx, "INSERT INTO schedule_latest (latest_time, network, chain_id, version, kind, task_id, hash, height, nonce, retry, error, error_class) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)",
+		lRec.LastTime, rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID, lRec.Hash, lRec.Height, lRec.Nonce, lRec.RetryCount, lRec.Error, lRec.ErrorClass)
+	return err
+}
+
+// GetHeights returns runs of the task stored after since, in order of time
+func (d *Driver) GetHeights(ctx context.Context, rcp coreStructs.RunConfigParams, since time.Time, limit uint64) (lRec []structures.LatestRecord, err error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT time, height, error FROM schedule_latest WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5 AND time > $6 ORDER BY time ASC LIMIT $7", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rc := structures.LatestRecord{TaskID: rcp.TaskID}
+		if err := rows.Scan(&rc.Time, &rc.Height, &rc.Error); err != nil {
+			return nil, err
+		}
+		lRec = append(lRec, rc)
+	}
+
+	return lRec, rows.Err()
+}
+
+func (d *Driver) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) (err error) {
+	_, err = d.db.ExecContext(ctx, "DELETE FROM schedule_latest WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
 	return err
 }
 
 func (d *Driver) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRec []structures.LatestRecord, err error) {
-	q := "SELECT hash, height, time, latest_time, nonce, retry, error, task_id  FROM schedule_latest "
+	q := "SELECT hash, height, time, latest_time, nonce, retry, error, error_class, task_id  FROM schedule_latest "
 
 	var (
 		args   []interface{}
@@ -101,7 +126,7 @@ func (d *Driver) GetRuns(ctx context.Context, kind, network, chainID, taskID str
 	defer rows.Close()
 	for rows.Next() {
 		rc := structures.LatestRecord{}
-		if err := rows.Scan(&rc.Hash, &rc.Height, &rc.Time, &rc.LastTime, &rc.Nonce, &rc.RetryCount, &rc.Error, &rc.TaskID); err != nil {
+		if err := rows.Scan(&rc.Hash, &rc.Height, &rc.Time, &rc.LastTime, &rc.Nonce, &rc.RetryCount, &rc.Error, &rc.ErrorClass, &rc.TaskID); err != nil {
 			return nil, err
 		}
 		lRec = append(lRec, rc)
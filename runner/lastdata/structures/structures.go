@@ -13,6 +13,7 @@ type LatestRecord struct {
 	Nonce      []byte    `json:"nonce"`
 	RetryCount uint64    `json:"retry_count"`
 	Error      []byte    `json:"error"`
+	ErrorClass string    `json:"error_class"`
 }
 
 type LatestDataRequest struct {
@@ -41,4 +42,6 @@ type LatestDataResponse struct {
 	Error      []byte    `json:"error"`
 
 	Processing bool `json:"processing"`
+	// More is set by the service that has more data ready to process, so the next request may be sent right away
+	More bool `json:"more"`
 }
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/figment-networks/indexer-scheduler/runner/plugin/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+// Plugin is a runner of out-of-tree task kind, served by external executable.
+// Requests and responses are exchanged as json lines over stdin and stdout of the process.
+// The process is started on the first run and started again after it exits.
+type Plugin struct {
+	cfg    structures.PluginConfig
+	logger *zap.Logger
+
+	proc     *process
+	procLock sync.Mutex
+	closed   bool
+
+	nextID uint64
+}
+
+func NewPlugin(logger *zap.Logger, cfg structures.PluginConfig) (*Plugin, error) {
+	if cfg.Kind == "" || cfg.Command == "" {
+		return nil, errors.New("plugin requires kind and command")
+	}
+
+	return &Plugin{
+		cfg:    cfg,
+		logger: logger,
+	}, nil
+}
+
+func (p *Plugin) Name() string {
+	return p.cfg.Kind
+}
+
+// RegisterHandles does nothing, plugins keep their state on their own
+func (p *Plugin) RegisterHandles(mux *http.ServeMux) {}
+
+func (p *Plugin) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (backoff bool, err error) {
+	proc, err := p.process()
+	if err != nil {
+		return true, &coreStructs.RunError{Contents: fmt.Errorf("error starting plugin [%s]: %w", p.cfg.Kind, err)}
+	}
+
+	resp, err := proc.call(ctx, structures.Request{ID: atomic.AddUint64(&p.nextID, 1), Params: rcp})
+	switch {
+	case errors.Is(err, ErrPluginExited):
+		backoff, err := proc.exitError()
+		p.logger.Error("[Plugin] Plugin exited", zap.String("kind", p.cfg.Kind), zap.Error(err))
+		return backoff, err
+	case err != nil:
+		return true, &coreStructs.RunError{Contents: fmt.Errorf("error calling plugin [%s]: %w", p.cfg.Kind, err)}
+	}
+
+	if resp.Error != nil {
+		return resp.Backoff, &coreStructs.RunError{Contents: fmt.Errorf("error in plugin [%s]: %s", p.cfg.Kind, resp.Error.Message), Unrecoverable: resp.Error.Unrecoverable}
+	}
+
+	if resp.Finished {
+		return false, io.EOF
+	}
+
+	return resp.Backoff, nil
+}
+
+// process returns running plugin process, starting it if it's not running
+func (p *Plugin) process() (*process, error) {
+	p.procLock.Lock()
+	defer p.procLock.Unlock()
+
+	if p.closed {
+		return nil, errors.New("plugin is closed")
+	}
+
+	if p.proc != nil && !p.proc.exited() {
+		return p.proc, nil
+	}
+
+	if p.proc != nil {
+		p.logger.Info("[Plugin] Restarting plugin", zap.String("kind", p.cfg.Kind), zap.String("command", p.cfg.Command))
+	} else {
+		p.logger.Info("[Plugin] Starting plugin", zap.String("kind", p.cfg.Kind), zap.String("command", p.cfg.Command))
+	}
+
+	proc, err := startProcess(p.logger, p.cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.proc = proc
+	return proc, nil
+}
+
+// Close stops the plugin process, it's not started again
+func (p *Plugin) Close() {
+	p.procLock.Lock()
+	defer p.procLock.Unlock()
+
+	p.closed = true
+	if p.proc != nil && !p.proc.exited() {
+		p.proc.stop()
+	}
+}
@@ -0,0 +1,190 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/runner/plugin/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+// TestHelperProcess is not a real test, it's the plugin executable started by the other tests
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 64*1024), 2*maxLineSize)
+	out := json.NewEncoder(os.Stdout)
+
+	next := func() (req structures.Request) {
+		if !in.Scan() {
+			os.Exit(0)
+		}
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			os.Exit(1)
+		}
+		return req
+	}
+
+	switch os.Getenv("HELPER_MODE") {
+	case "unrecoverable":
+		next()
+		os.Exit(ExitCodeUnrecoverable)
+	case "crash_once":
+		// the first process crashes, the restarted one serves the runs
+		state := os.Getenv("HELPER_STATE")
+		if _, err := os.Stat(state); os.IsNotExist(err) {
+			next()
+			os.WriteFile(state, nil, 0600)
+			os.Exit(1)
+		}
+	case "reorder":
+		// responses are written in the reverse order of requests
+		first, second := next(), next()
+		for _, req := range []structures.Request{second, first} {
+			out.Encode(structures.Response{ID: req.ID, Finished: req.Params.TaskID == "finish"})
+		}
+	case "oversized":
+		next()
+		fmt.Println(`{"id":1,"padding":"` + strings.Repeat("x", maxLineSize) + `"}`)
+	case "stuck":
+		// never reads the input
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+
+	for {
+		req := next()
+		if req.Cancel {
+			continue
+		}
+		out.Encode(structures.Response{ID: req.ID})
+	}
+}
+
+func helperPlugin(t *testing.T, mode string, env ...string) *Plugin {
+	t.Helper()
+
+	p, err := NewPlugin(zap.NewNop(), structures.PluginConfig{
+		Kind:    "helper",
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperProcess"},
+		Env:     append([]string{"GO_WANT_HELPER_PROCESS=1", "HELPER_MODE=" + mode}, env...),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(p.Close)
+	return p
+}
+
+func runError(t *testing.T, err error) *coreStructs.RunError {
+	t.Helper()
+
+	var rErr *coreStructs.RunError
+	if !errors.As(err, &rErr) {
+		t.Fatalf("error = %v, want RunError", err)
+	}
+	return rErr
+}
+
+func TestRunUnrecoverableExit(t *testing.T) {
+	p := helperPlugin(t, "unrecoverable")
+
+	backoff, err := p.Run(context.Background(), coreStructs.RunConfigParams{TaskID: "t"})
+	if rErr := runError(t, err); rErr.IsRecoverable() || !errors.Is(rErr.Contents, ErrPluginExited) {
+		t.Errorf("error = %v, want unrecoverable exit", err)
+	}
+	if backoff {
+		t.Error("unrecoverable exit should not back off")
+	}
+}
+
+func TestRunRestartsAfterCrash(t *testing.T) {
+	p := helperPlugin(t, "crash_once", "HELPER_STATE="+t.TempDir()+"/crashed")
+
+	backoff, err := p.Run(context.Background(), coreStructs.RunConfigParams{TaskID: "t"})
+	if rErr := runError(t, err); !rErr.IsRecoverable() || !errors.Is(rErr.Contents, ErrPluginExited) {
+		t.Errorf("error = %v, want recoverable exit", err)
+	}
+	if !backoff {
+		t.Error("crash should back off")
+	}
+
+	if backoff, err = p.Run(context.Background(), coreStructs.RunConfigParams{TaskID: "t"}); err != nil || backoff {
+		t.Errorf("run after restart = (%t, %v), want (false, nil)", backoff, err)
+	}
+}
+
+func TestRunOutOfOrderResponses(t *testing.T) {
+	p := helperPlugin(t, "reorder")
+
+	wg := sync.WaitGroup{}
+	errs := make(map[string]error)
+	errsLock := sync.Mutex{}
+	for _, taskID := range []string{"finish", "continue"} {
+		wg.Add(1)
+		go func(taskID string) {
+			defer wg.Done()
+			_, err := p.Run(context.Background(), coreStructs.RunConfigParams{TaskID: taskID})
+			errsLock.Lock()
+			errs[taskID] = err
+			errsLock.Unlock()
+		}(taskID)
+	}
+	wg.Wait()
+
+	if errs["finish"] != io.EOF {
+		t.Errorf("finished task error = %v, want EOF", errs["finish"])
+	}
+	if errs["continue"] != nil {
+		t.Errorf("continued task error = %v, want nil", errs["continue"])
+	}
+}
+
+func TestRunOversizedLine(t *testing.T) {
+	p := helperPlugin(t, "oversized")
+
+	backoff, err := p.Run(context.Background(), coreStructs.RunConfigParams{TaskID: "t"})
+	if rErr := runError(t, err); !rErr.IsRecoverable() || !errors.Is(rErr.Contents, ErrPluginExited) {
+		t.Errorf("error = %v, want recoverable exit", err)
+	}
+	if !backoff {
+		t.Error("stopped plugin should back off")
+	}
+}
+
+func TestRunWriteTimeout(t *testing.T) {
+	p := helperPlugin(t, "stuck")
+
+	// request greater than pipe buffer blocks, as the plugin does not read it
+	rcp := coreStructs.RunConfigParams{TaskID: "t", Config: map[string]interface{}{"padding": strings.Repeat("x", 1024*1024)}}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := p.Run(ctx, rcp); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("run returned after %s, want right after its context ended", elapsed)
+	}
+
+	select {
+	case <-p.proc.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stuck plugin was not killed")
+	}
+}
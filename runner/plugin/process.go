@@ -0,0 +1,212 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/runner/plugin/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+	"go.uber.org/zap"
+)
+
+// ExitCodeUnrecoverable is the exit code of plugin that cannot continue, schedules waiting for it are failed
+const ExitCodeUnrecoverable = 3
+
+// maxLineSize is the maximum size of a single response line
+const maxLineSize = 10 * 1024 * 1024
+
+// cancelWriteTimeout is the time given to plugin to take the cancel request, after the run is not awaited anymore
+const cancelWriteTimeout = time.Second
+
+var ErrPluginExited = errors.New("plugin exited")
+
+// process is a single running instance of plugin executable
+type process struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeLock sync.Mutex
+
+	pending     map[uint64]chan structures.Response
+	pendingLock sync.Mutex
+
+	// done is closed after the process exits, exitErr is set before
+	done    chan struct{}
+	exitErr error
+}
+
+func startProcess(logger *zap.Logger, cfg structures.PluginConfig) (*process, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Env = append(os.Environ(), cfg.Env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &process{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[uint64]chan structures.Response),
+		done:    make(chan struct{}),
+	}
+
+	go logStderr(logger, cfg.Kind, stderr)
+	go p.read(logger, cfg.Kind, stdout)
+
+	return p, nil
+}
+
+// read dispatches responses to the waiting runs, until stdout is closed
+func (p *process) read(logger *zap.Logger, kind string, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		resp := structures.Response{}
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			logger.Warn("[Plugin] Error decoding response", zap.String("kind", kind), zap.Error(err))
+			continue
+		}
+
+		p.pendingLock.Lock()
+		ch, ok := p.pending[resp.ID]
+		delete(p.pending, resp.ID)
+		p.pendingLock.Unlock()
+
+		if !ok {
+			logger.Warn("[Plugin] Outstanding response passed", zap.String("kind", kind), zap.Uint64("id", resp.ID))
+			continue
+		}
+		ch <- resp
+	}
+
+	// process is not usable without its output
+	if err := scanner.Err(); err != nil {
+		logger.Error("[Plugin] Error reading output, stopping plugin", zap.String("kind", kind), zap.Error(err))
+		p.cmd.Process.Kill()
+	}
+
+	p.exitErr = p.cmd.Wait()
+	close(p.done)
+}
+
+// call sends the request and waits for the response, process exit or context end
+func (p *process) call(ctx context.Context, req structures.Request) (resp structures.Response, err error) {
+	ch := make(chan structures.Response, 1)
+	p.pendingLock.Lock()
+	p.pending[req.ID] = ch
+	p.pendingLock.Unlock()
+
+	defer func() {
+		p.pendingLock.Lock()
+		delete(p.pending, req.ID)
+		p.pendingLock.Unlock()
+	}()
+
+	if err := p.write(ctx, req); err != nil {
+		return resp, fmt.Errorf("error writing request: %w", err)
+	}
+
+	select {
+	case resp = <-ch:
+		return resp, nil
+	case <-p.done:
+		return resp, ErrPluginExited
+	case <-ctx.Done():
+		cCtx, cCancel := context.WithTimeout(context.Background(), cancelWriteTimeout)
+		defer cCancel()
+		p.write(cCtx, structures.Request{ID: req.ID, Cancel: true})
+		return resp, ctx.Err()
+	}
+}
+
+// write sends the request from a goroutine, so the plugin that does not read its input cannot block the run past its context.
+// Such process is killed, the next run starts it again.
+func (p *process) write(ctx context.Context, req structures.Request) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		p.writeLock.Lock()
+		defer p.writeLock.Unlock()
+		_, err := p.stdin.Write(append(b, '\n'))
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-p.done:
+		return ErrPluginExited
+	case <-ctx.Done():
+		p.cmd.Process.Kill()
+		return ctx.Err()
+	}
+}
+
+// exited reports if the process is not running anymore
+func (p *process) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// exitError maps the exit code of process to the error of run.
+// Clean exit is retried right away, while crashes back off.
+func (p *process) exitError() (backoff bool, err error) {
+	var exitErr *exec.ExitError
+	if !errors.As(p.exitErr, &exitErr) {
+		if p.exitErr != nil {
+			return true, &coreStructs.RunError{Contents: fmt.Errorf("%w: %s", ErrPluginExited, p.exitErr.Error())}
+		}
+		return false, &coreStructs.RunError{Contents: ErrPluginExited}
+	}
+
+	switch exitErr.ExitCode() {
+	case ExitCodeUnrecoverable:
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("%w: %s", ErrPluginExited, exitErr.Error()), Unrecoverable: true}
+	default:
+		return true, &coreStructs.RunError{Contents: fmt.Errorf("%w: %s", ErrPluginExited, exitErr.Error())}
+	}
+}
+
+func (p *process) stop() {
+	p.stdin.Close()
+	if p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+	<-p.done
+}
+
+func logStderr(logger *zap.Logger, kind string, stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		logger.Info("[Plugin] stderr", zap.String("kind", kind), zap.String("line", scanner.Text()))
+	}
+}
@@ -0,0 +1,36 @@
+package structures
+
+import (
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+)
+
+// PluginConfig describes the executable serving runs of a single kind
+type PluginConfig struct {
+	Kind    string   `json:"kind"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	// Env is appended to the environment of scheduler, in form of `KEY=value`
+	Env []string `json:"env"`
+}
+
+// Request is written to plugin stdin as a single line.
+// Request with Cancel set informs that the run of given ID is not awaited anymore.
+type Request struct {
+	ID     uint64                      `json:"id"`
+	Params coreStructs.RunConfigParams `json:"params,omitempty"`
+	Cancel bool                        `json:"cancel,omitempty"`
+}
+
+// Response is read from plugin stdout as a single line, matched with request by ID
+type Response struct {
+	ID      uint64 `json:"id"`
+	Backoff bool   `json:"backoff"`
+	// Finished reports that the task has nothing more to do, schedule is finished
+	Finished bool           `json:"finished"`
+	Error    *ResponseError `json:"error,omitempty"`
+}
+
+type ResponseError struct {
+	Message       string `json:"message"`
+	Unrecoverable bool   `json:"unrecoverable"`
+}
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/figment-networks/indexer-scheduler/runner/syncrange/structures"
 	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
@@ -10,7 +11,11 @@ import (
 type PDriver interface {
 	GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.SyncRecord, error)
 	SetLatest(ctx context.Context, rcp coreStructs.RunConfigParams, latest structures.SyncRecord) error
+	GetLatestChunks(ctx context.Context, rcp coreStructs.RunConfigParams) ([]structures.SyncRecord, error)
+	GetLatestAt(ctx context.Context, rcp coreStructs.RunConfigParams, at time.Time) ([]structures.SyncRecord, error)
+	GetRunStats(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.RunStats, error)
 	GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRec []structures.SyncRecord, err error)
+	Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error
 }
 
 type SyncRangeStorageTransport struct {
@@ -31,6 +36,22 @@ func (s *SyncRangeStorageTransport) SetLatest(ctx context.Context, rcp coreStruc
 	return s.Driver.SetLatest(ctx, rcp, latest)
 }
 
+func (s *SyncRangeStorageTransport) GetLatestChunks(ctx context.Context, rcp coreStructs.RunConfigParams) ([]structures.SyncRecord, error) {
+	return s.Driver.GetLatestChunks(ctx, rcp)
+}
+
+func (s *SyncRangeStorageTransport) GetLatestAt(ctx context.Context, rcp coreStructs.RunConfigParams, at time.Time) ([]structures.SyncRecord, error) {
+	return s.Driver.GetLatestAt(ctx, rcp, at)
+}
+
+func (s *SyncRangeStorageTransport) GetRunStats(ctx context.Context, rcp coreStructs.RunConfigParams) (structures.RunStats, error) {
+	return s.Driver.GetRunStats(ctx, rcp)
+}
+
 func (s *SyncRangeStorageTransport) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRec []structures.SyncRecord, err error) {
 	return s.Driver.GetRuns(ctx, kind, network, chainID, taskID, limit, offset)
 }
+
+func (s *SyncRangeStorageTransport) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return s.Driver.Purge(ctx, rcp)
+}
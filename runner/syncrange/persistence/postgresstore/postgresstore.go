@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/figment-networks/indexer-scheduler/persistence/params"
 	"github.com/figment-networks/indexer-scheduler/runner/syncrange/structures"
@@ -22,10 +23,11 @@ func NewDriver(db *sql.DB) *Driver {
 	}
 }
 
+// GetLatest returns the latest record of not chunked task
 func (d *Driver) GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams) (lRec structures.SyncRecord, err error) {
-	row := d.db.QueryRowContext(ctx, "SELECT hash, height, latest_time, time,  nonce, retry, task_id FROM schedule_syncrange WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5 ORDER BY time DESC LIMIT 1", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+	row := d.db.QueryRowContext(ctx, "SELECT hash, height, latest_time, time, COALESCE(started_at, time), nonce, retry, error, task_id, chunk FROM schedule_syncrange WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5 AND chunk = -1 ORDER BY time DESC LIMIT 1", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
 	if row != nil {
-		if err := row.Scan(&lRec.Hash, &lRec.Height, &lRec.LastTime, &lRec.Time, &lRec.Nonce, &lRec.RetryCount, &lRec.TaskID); err != nil {
+		if err := row.Scan(&lRec.Hash, &lRec.Height, &lRec.LastTime, &lRec.Time, &lRec.StartedAt, &lRec.Nonce, &lRec.RetryCount, &lRec.Error, &lRec.TaskID, &lRec.Chunk); err != nil {
 			if err == sql.ErrNoRows {
 				return lRec, params.ErrNotFound
 			}
@@ -36,13 +38,57 @@ func (d *Driver) GetLatest(ctx context.Context, rcp coreStructs.RunConfigParams)
 }
 
 func (d *Driver) SetLatest(ctx context.Context, rcp coreStructs.RunConfigParams, lRec structures.SyncRecord) (err error) {
-	_, err = d.db.ExecContext(ctx, "INSERT INTO schedule_syncrange (latest_time, network, chain_id, version, kind, task_id, hash, height, nonce, retry, error) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)",
-		lRec.LastTime, rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID, lRec.Hash, lRec.Height, lRec.Nonce, lRec.RetryCount, lRec.Error)
+	_, err = d.db.ExecContext(ctx, "INSERT INTO schedule_syncrange (latest_time, network, chain_id, version, kind, task_id, hash, height, nonce, retry, error, error_class, chunk, started_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)",
+		lRec.LastTime, rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID, lRec.Hash, lRec.Height, lRec.Nonce, lRec.RetryCount, lRec.Error, lRec.ErrorClass, lRec.Chunk, sql.NullTime{Time: lRec.StartedAt, Valid: !lRec.StartedAt.IsZero()})
+	return err
+}
+
+// GetLatestChunks returns the latest record of every chunk of the task
+func (d *Driver) GetLatestChunks(ctx context.Context, rcp coreStructs.RunConfigParams) (lRecs []structures.SyncRecord, err error) {
+	return d.getLatestPerChunk(ctx, "SELECT DISTINCT ON (chunk) hash, height, latest_time, time, COALESCE(started_at, time), nonce, retry, error, task_id, chunk FROM schedule_syncrange WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5 AND chunk >= 0 ORDER BY chunk, time DESC", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+}
+
+// GetLatestAt returns the latest record of every chunk of the task stored not later than at, including not chunked one
+func (d *Driver) GetLatestAt(ctx context.Context, rcp coreStructs.RunConfigParams, at time.Time) (lRecs []structures.SyncRecord, err error) {
+	return d.getLatestPerChunk(ctx, "SELECT DISTINCT ON (chunk) hash, height, latest_time, time, COALESCE(started_at, time), nonce, retry, error, task_id, chunk FROM schedule_syncrange WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5 AND time <= $6 ORDER BY chunk, time DESC", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID, at)
+}
+
+func (d *Driver) getLatestPerChunk(ctx context.Context, q string, args ...interface{}) (lRecs []structures.SyncRecord, err error) {
+	rows, err := d.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		lRec := structures.SyncRecord{}
+		if err := rows.Scan(&lRec.Hash, &lRec.Height, &lRec.LastTime, &lRec.Time, &lRec.StartedAt, &lRec.Nonce, &lRec.RetryCount, &lRec.Error, &lRec.TaskID, &lRec.Chunk); err != nil {
+			return nil, err
+		}
+		lRecs = append(lRecs, lRec)
+	}
+
+	return lRecs, rows.Err()
+}
+
+// GetRunStats counts the stored runs of the task and the failed ones
+func (d *Driver) GetRunStats(ctx context.Context, rcp coreStructs.RunConfigParams) (rs structures.RunStats, err error) {
+	startedAt := sql.NullTime{}
+	row := d.db.QueryRowContext(ctx, "SELECT COUNT(*), COUNT(*) FILTER (WHERE error IS NOT NULL AND error <> ''), MIN(COALESCE(started_at, time)) FROM schedule_syncrange WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
+	if err := row.Scan(&rs.Runs, &rs.Errors, &startedAt); err != nil {
+		return rs, err
+	}
+	rs.StartedAt = startedAt.Time
+	return rs, nil
+}
+
+func (d *Driver) Purge(ctx context.Context, rcp coreStructs.RunConfigParams) (err error) {
+	_, err = d.db.ExecContext(ctx, "DELETE FROM schedule_syncrange WHERE network = $1 AND chain_id = $2 AND version = $3 AND kind = $4 AND task_id = $5", rcp.Network, rcp.ChainID, rcp.Version, rcp.Kind, rcp.TaskID)
 	return err
 }
 
 func (d *Driver) GetRuns(ctx context.Context, kind, network, chainID, taskID string, limit, offset uint64) (lRec []structures.SyncRecord, err error) {
-	q := "SELECT hash, height, time, latest_time, nonce, retry, error, task_id  FROM schedule_syncrange "
+	q := "SELECT hash, height, time, COALESCE(started_at, time), latest_time, nonce, retry, error, error_class, task_id, chunk  FROM schedule_syncrange "
 
 	var (
 		args   []interface{}
@@ -101,7 +147,7 @@ func (d *Driver) GetRuns(ctx context.Context, kind, network, chainID, taskID str
 	defer rows.Close()
 	for rows.Next() {
 		rc := structures.SyncRecord{}
-		if err := rows.Scan(&rc.Hash, &rc.Height, &rc.Time, &rc.LastTime, &rc.Nonce, &rc.RetryCount, &rc.Error, &rc.TaskID); err != nil {
+		if err := rows.Scan(&rc.Hash, &rc.Height, &rc.Time, &rc.StartedAt, &rc.LastTime, &rc.Nonce, &rc.RetryCount, &rc.Error, &rc.ErrorClass, &rc.TaskID, &rc.Chunk); err != nil {
 			return nil, err
 		}
 		lRec = append(lRec, rc)
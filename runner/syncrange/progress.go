@@ -0,0 +1,186 @@
+package syncrange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/figment-networks/indexer-scheduler/http/auth"
+	"github.com/figment-networks/indexer-scheduler/runner/syncrange/structures"
+	coreStructs "github.com/figment-networks/indexer-scheduler/structures"
+)
+
+// defaultProgressWindow is the period throughput is measured over, when not given in request
+const defaultProgressWindow = 10 * time.Minute
+
+// ScheduleGetter returns all the stored schedules
+type ScheduleGetter interface {
+	GetConfigs(ctx context.Context) (rcs []coreStructs.RunConfig, err error)
+}
+
+// Progress computes the state of synchronization of schedule range, with throughput measured over the window preceding now
+func (c *Client) Progress(ctx context.Context, rc coreStructs.RunConfig, window time.Duration, now time.Time) (p structures.Progress, err error) {
+	mi, ok := SyncRangeFromMapInterface(rc.Config)
+	if !ok {
+		return p, fmt.Errorf("error parsing syncrange config:  %+v", rc.Config)
+	}
+
+	p = structures.Progress{
+		Network:    rc.Network,
+		ChainID:    rc.ChainID,
+		Version:    rc.Version,
+		TaskID:     rc.TaskID,
+		HeightFrom: mi.HeightFrom,
+		HeightTo:   mi.HeightTo,
+		Chunks:     mi.Chunks,
+		Window:     window.String(),
+	}
+
+	rcp := coreStructs.RunConfigParams{Network: rc.Network, ChainID: rc.ChainID, Version: rc.Version, Kind: rc.Kind, TaskID: rc.TaskID}
+	stats, err := c.store.GetRunStats(ctx, rcp)
+	if err != nil {
+		return p, fmt.Errorf("error getting run stats: %w", err)
+	}
+	p.Runs, p.Errors, p.StartedAt = stats.Runs, stats.Errors, stats.StartedAt
+
+	current, err := c.store.GetLatestAt(ctx, rcp, now)
+	if err != nil {
+		return p, fmt.Errorf("error getting latest records: %w", err)
+	}
+
+	chunks := []chunkRange{{Chunk: -1, HeightFrom: mi.HeightFrom, HeightTo: mi.HeightTo}}
+	if mi.Chunks > 1 {
+		chunks = splitRange(mi)
+	}
+
+	var unfinished int
+	p.Synced, unfinished = synced(chunks, current)
+	p.Finished = unfinished == 0
+	if total := mi.HeightTo - mi.HeightFrom; mi.HeightTo > mi.HeightFrom {
+		p.Percent = float64(p.Synced) / float64(total) * 100
+	} else if p.Finished {
+		p.Percent = 100
+	}
+
+	for _, r := range current {
+		if !hasChunk(chunks, r.Chunk) {
+			continue
+		}
+		if r.Time.After(p.LastRun) {
+			p.LastRun = r.Time
+			p.LastError = string(r.Error)
+		}
+		p.RetryCount += r.RetryCount
+	}
+
+	// schedule started within the window is measured since its start
+	windowStart := now.Add(-window)
+	if stats.StartedAt.After(windowStart) {
+		windowStart = stats.StartedAt
+	}
+	past, err := c.store.GetLatestAt(ctx, rcp, windowStart)
+	if err != nil {
+		return p, fmt.Errorf("error getting past records: %w", err)
+	}
+	pastSynced, _ := synced(chunks, past)
+
+	if elapsed := now.Sub(windowStart); elapsed > 0 && p.Synced > pastSynced {
+		p.BlocksPerMinute = float64(p.Synced-pastSynced) / elapsed.Minutes()
+		if !p.Finished && mi.HeightTo > mi.HeightFrom+p.Synced {
+			remaining := float64(mi.HeightTo - mi.HeightFrom - p.Synced)
+			p.ETA = now.Add(time.Duration(remaining / p.BlocksPerMinute * float64(time.Minute))).Truncate(time.Second)
+		}
+	}
+
+	return p, nil
+}
+
+func hasChunk(chunks []chunkRange, chunk int64) bool {
+	for _, cr := range chunks {
+		if cr.Chunk == chunk {
+			return true
+		}
+	}
+	return false
+}
+
+// synced sums the synchronized heights of all the chunks, returning also the number of unfinished ones
+func synced(chunks []chunkRange, recs []structures.SyncRecord) (sum uint64, unfinished int) {
+	heights := make(map[int64]uint64, len(recs))
+	for _, r := range recs {
+		heights[r.Chunk] = r.Height
+	}
+
+	for _, cr := range chunks {
+		h := heights[cr.Chunk]
+		if h == 0 || h < cr.HeightTo {
+			unfinished++
+		}
+		if h > cr.HeightTo {
+			h = cr.HeightTo
+		}
+		if h > cr.HeightFrom {
+			sum += h - cr.HeightFrom
+		}
+	}
+	return sum, unfinished
+}
+
+// handlerProgress lists the progress of syncrange schedules, optionally filtered by `network`, `chain_id` and `task_id` query parameters.
+// Throughput is measured over the `window` (e.g. `30m`), 10 minutes by default.
+func (c *Client) handlerProgress(w http.ResponseWriter, r *http.Request) {
+	if err := auth.BasicAuth(c.creds, w, r); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	w.Header().Add("Content-type", "application/json")
+
+	if c.schedules == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		enc.Encode(string(`{"error":"progress reporting is not enabled"}`))
+		return
+	}
+
+	q := r.URL.Query()
+	window := defaultProgressWindow
+	if ws := q.Get("window"); ws != "" {
+		var err error
+		if window, err = time.ParseDuration(ws); err != nil || window <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(string(`{"error":"wrong window"}`))
+			return
+		}
+	}
+
+	rcs, err := c.schedules.GetConfigs(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+		return
+	}
+
+	now := time.Now()
+	progress := []structures.Progress{}
+	for _, rc := range rcs {
+		if rc.Kind != RunnerName || rc.Status == coreStructs.StateArchived ||
+			(q.Get("network") != "" && rc.Network != q.Get("network")) ||
+			(q.Get("chain_id") != "" && rc.ChainID != q.Get("chain_id")) ||
+			(q.Get("task_id") != "" && rc.TaskID != q.Get("task_id")) {
+			continue
+		}
+
+		p, err := c.Progress(r.Context(), rc, window, now)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			enc.Encode(string(`{"error":"` + err.Error() + `"}`))
+			return
+		}
+		progress = append(progress, p)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	enc.Encode(progress)
+}
@@ -5,14 +5,20 @@ import (
 )
 
 type SyncRecord struct {
-	TaskID     string    `json:"task_id"`
-	Time       time.Time `json:"time"`
+	TaskID string    `json:"task_id"`
+	Time   time.Time `json:"time"`
+	// StartedAt is the time of sending request, Time is the time of storing its result
+	StartedAt  time.Time `json:"started_at"`
 	Hash       string    `json:"hash"`
 	Height     uint64    `json:"height"`
 	LastTime   time.Time `json:"last_time"`
 	Nonce      []byte    `json:"nonce"`
 	RetryCount uint64    `json:"retry_count"`
 	Error      []byte    `json:"error"`
+	ErrorClass string    `json:"error_class"`
+
+	// Chunk is the number of part of range in chunked schedule, -1 for not chunked one
+	Chunk int64 `json:"chunk"`
 }
 
 type SyncDataRequest struct {
@@ -45,3 +51,39 @@ type SyncDataResponse struct {
 
 	Processing bool `json:"processing"`
 }
+
+// RunStats summarizes all the stored runs of the task
+type RunStats struct {
+	Runs      uint64
+	Errors    uint64
+	StartedAt time.Time
+}
+
+// Progress is the state of synchronization of the range of single schedule
+type Progress struct {
+	Network string `json:"network"`
+	ChainID string `json:"chain_id"`
+	Version string `json:"version"`
+	TaskID  string `json:"task_id"`
+
+	HeightFrom uint64 `json:"height_from"`
+	HeightTo   uint64 `json:"height_to"`
+	Chunks     uint64 `json:"chunks"`
+
+	// Synced is the number of synchronized heights, Percent is its share in the whole range
+	Synced   uint64  `json:"synced"`
+	Percent  float64 `json:"percent"`
+	Finished bool    `json:"finished"`
+
+	// BlocksPerMinute is measured over the sliding window, ETA is zero when it is unknown
+	BlocksPerMinute float64   `json:"blocks_per_minute"`
+	Window          string    `json:"window"`
+	ETA             time.Time `json:"eta"`
+
+	StartedAt  time.Time `json:"started_at"`
+	LastRun    time.Time `json:"last_run"`
+	Runs       uint64    `json:"runs"`
+	Errors     uint64    `json:"errors"`
+	RetryCount uint64    `json:"retry_count"`
+	LastError  string    `json:"last_error,omitempty"`
+}
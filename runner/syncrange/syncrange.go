@@ -2,10 +2,14 @@ package syncrange
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/figment-networks/indexer-scheduler/http/auth"
 	"github.com/figment-networks/indexer-scheduler/persistence/params"
@@ -29,6 +33,9 @@ const RunnerName = "syncrange"
 type SyncRangeConfig struct {
 	HeightFrom uint64 `json:"height_from"`
 	HeightTo   uint64 `json:"height_to"`
+
+	// Chunks is the number of parts of range synchronized concurrently, zero or one means serial synchronization
+	Chunks uint64 `json:"chunks"`
 }
 
 func SyncRangeFromMapInterface(a map[string]interface{}) (src SyncRangeConfig, ok bool) {
@@ -58,17 +65,74 @@ func SyncRangeFromMapInterface(a map[string]interface{}) (src SyncRangeConfig, o
 	} else {
 		return src, false
 	}
+	if c, ok := a["chunks"]; ok {
+		if src.Chunks, err = chunksFromInterface(c); err != nil {
+			return src, false
+		}
+	}
 
 	return src, true
 }
 
+// chunksFromInterface reads the number of chunks, given as a string or a number (as decoded from json)
+func chunksFromInterface(c interface{}) (uint64, error) {
+	switch cc := c.(type) {
+	case string:
+		return strconv.ParseUint(cc, 10, 64)
+	case json.Number:
+		return strconv.ParseUint(cc.String(), 10, 64)
+	case float64:
+		if cc < 0 || cc != math.Trunc(cc) {
+			return 0, fmt.Errorf("chunks has to be a non negative integer: %v", cc)
+		}
+		return uint64(cc), nil
+	default:
+		return 0, fmt.Errorf("wrong type of chunks: %T", c)
+	}
+}
+
+// chunkRange is a part of range of chunked schedule
+type chunkRange struct {
+	Chunk      int64
+	HeightFrom uint64
+	HeightTo   uint64
+}
+
+// splitRange divides the range into chunks of equal size. Adjacent chunks share the boundary height,
+// so it is synchronized no matter if the worker treats the starting height as already synchronized.
+func splitRange(src SyncRangeConfig) (chunks []chunkRange) {
+	if src.HeightTo <= src.HeightFrom {
+		return []chunkRange{{Chunk: 0, HeightFrom: src.HeightFrom, HeightTo: src.HeightTo}}
+	}
+
+	n := src.Chunks
+	if n == 0 {
+		n = 1
+	}
+	if total := src.HeightTo - src.HeightFrom; n > total {
+		n = total
+	}
+	size := (src.HeightTo - src.HeightFrom + n - 1) / n
+
+	for from := src.HeightFrom; from < src.HeightTo; from += size {
+		to := from + size
+		if to > src.HeightTo {
+			to = src.HeightTo
+		}
+		chunks = append(chunks, chunkRange{Chunk: int64(len(chunks)), HeightFrom: from, HeightTo: to})
+	}
+	return chunks
+}
+
 type Client struct {
 	transport map[string]SyncRangeTransporter
 	dest      TargetGetter
 
-	store  *persistence.SyncRangeStorageTransport
-	logger *zap.Logger
-	m      *monitor.Monitor
+	store     *persistence.SyncRangeStorageTransport
+	schedules ScheduleGetter
+	logger    *zap.Logger
+	creds     auth.AuthCredentials
+	m         *monitor.Monitor
 }
 
 func NewClient(logger *zap.Logger, store *persistence.SyncRangeStorageTransport, creds auth.AuthCredentials, dest TargetGetter) *Client {
@@ -77,6 +141,7 @@ func NewClient(logger *zap.Logger, store *persistence.SyncRangeStorageTransport,
 		dest:      dest,
 		transport: make(map[string]SyncRangeTransporter),
 		logger:    logger,
+		creds:     creds,
 		m:         monitor.NewMonitor(store, creds),
 	}
 }
@@ -89,8 +154,127 @@ func (c *Client) Name() string {
 	return RunnerName
 }
 
+// SetScheduleGetter enables the progress reporting, which needs the ranges of schedules
+func (c *Client) SetScheduleGetter(sg ScheduleGetter) {
+	c.schedules = sg
+}
+
 func (c *Client) RegisterHandles(mux *http.ServeMux) {
 	c.m.RegisterHandles(mux)
+	mux.HandleFunc("/scheduler/runner/syncrange/progress", c.handlerProgress)
+}
+
+// LatestStatus returns the state of the latest stored run of the task.
+// For chunked task the height is the one up to which the whole range is synchronized.
+func (c *Client) LatestStatus(ctx context.Context, rcp coreStructs.RunConfigParams) (coreStructs.LatestStatus, error) {
+	mi, err := c.rangeOf(ctx, rcp)
+	if err != nil {
+		return coreStructs.LatestStatus{}, err
+	}
+	if mi.Chunks > 1 {
+		return c.chunkedStatus(ctx, rcp, mi)
+	}
+
+	latest, err := c.store.GetLatest(ctx, rcp)
+	if err != nil {
+		return coreStructs.LatestStatus{}, err
+	}
+
+	return coreStructs.LatestStatus{
+		Height:    latest.Height,
+		Time:      latest.Time,
+		Succeeded: len(latest.Error) == 0,
+	}, nil
+}
+
+// rangeOf returns the range config of task. Params of dependencies carry no config, so it is taken from the stored schedule.
+func (c *Client) rangeOf(ctx context.Context, rcp coreStructs.RunConfigParams) (SyncRangeConfig, error) {
+	if mi, ok := SyncRangeFromMapInterface(rcp.Config); ok || c.schedules == nil {
+		return mi, nil
+	}
+
+	rcs, err := c.schedules.GetConfigs(ctx)
+	if err != nil {
+		return SyncRangeConfig{}, fmt.Errorf("error getting schedules: %w", err)
+	}
+	for _, rc := range rcs {
+		if rc.Kind == rcp.Kind && rc.Network == rcp.Network && rc.ChainID == rcp.ChainID && rc.Version == rcp.Version && rc.TaskID == rcp.TaskID && rc.Status != coreStructs.StateArchived {
+			mi, _ := SyncRangeFromMapInterface(rc.Config)
+			return mi, nil
+		}
+	}
+	return SyncRangeConfig{}, nil
+}
+
+func (c *Client) chunkedStatus(ctx context.Context, rcp coreStructs.RunConfigParams, mi SyncRangeConfig) (status coreStructs.LatestStatus, err error) {
+	recs, err := c.store.GetLatestChunks(ctx, rcp)
+	if err != nil {
+		return status, err
+	}
+	if len(recs) == 0 {
+		return status, params.ErrNotFound
+	}
+
+	heights := make(map[int64]uint64, len(recs))
+	for _, r := range recs {
+		heights[r.Chunk] = r.Height
+		if r.Time.After(status.Time) {
+			status.Time = r.Time
+			status.Succeeded = len(r.Error) == 0
+		}
+	}
+	status.Height = contiguousHeight(splitRange(mi), heights)
+	return status, nil
+}
+
+// contiguousHeight returns the height up to which all the chunks are synchronized, zero if the first chunk made no progress
+func contiguousHeight(chunks []chunkRange, heights map[int64]uint64) uint64 {
+	var contiguous uint64
+	for i, cr := range chunks {
+		h := heights[cr.Chunk]
+		if h != 0 && h >= cr.HeightTo {
+			contiguous = cr.HeightTo
+			continue
+		}
+		// the starting height of chunk is the end of previous one
+		if i > 0 && h < cr.HeightFrom {
+			return cr.HeightFrom
+		}
+		return h
+	}
+	return contiguous
+}
+
+// PurgeHistory removes all the stored runs of given task
+func (c *Client) PurgeHistory(ctx context.Context, rcp coreStructs.RunConfigParams) error {
+	return c.store.Purge(ctx, rcp)
+}
+
+// ResolveTargets chooses the target of the next run, chunked range gets one for every unfinished chunk
+func (c *Client) ResolveTargets(ctx context.Context, rcp coreStructs.RunConfigParams) (targets []coreStructs.Target) {
+	mi, ok := SyncRangeFromMapInterface(rcp.Config)
+	if !ok {
+		return nil
+	}
+
+	count := 1
+	if mi.Chunks > 1 {
+		recs, err := c.store.GetLatestChunks(ctx, rcp)
+		if err != nil {
+			return nil
+		}
+		pending, _ := pendingChunks(splitRange(mi), recs)
+		count = len(pending)
+	}
+
+	for i := 0; i < count; i++ {
+		t, ok := c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID})
+		if !ok {
+			return nil
+		}
+		targets = append(targets, t)
+	}
+	return targets
 }
 
 func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (backoff bool, err error) {
@@ -99,6 +283,11 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 	if !ok {
 		return false, &coreStructs.RunError{Contents: fmt.Errorf("error parsing syncrange config:  %+v", rcp.Config)}
 	}
+
+	if mi.Chunks > 1 {
+		return c.runChunks(ctx, rcp, mi)
+	}
+
 	latest, err := c.store.GetLatest(ctx, rcp)
 	if err != nil && err != params.ErrNotFound {
 		return false, &coreStructs.RunError{Contents: fmt.Errorf("error getting data from store GetLatest [%s]:  %w", RunnerName, err)}
@@ -108,6 +297,89 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 		return false, io.EOF
 	}
 
+	return c.runRange(ctx, rcp, latest, chunkRange{Chunk: -1, HeightFrom: mi.HeightFrom, HeightTo: mi.HeightTo}, 0)
+}
+
+// runChunks synchronizes all the unfinished chunks of range concurrently, every one against the next available target.
+// Failed chunks are retried in the next run, the schedule backs off only when none of the chunks made progress.
+func (c *Client) runChunks(ctx context.Context, rcp coreStructs.RunConfigParams, mi SyncRangeConfig) (backoff bool, err error) {
+	recs, err := c.store.GetLatestChunks(ctx, rcp)
+	if err != nil {
+		return false, &coreStructs.RunError{Contents: fmt.Errorf("error getting data from store GetLatestChunks [%s]:  %w", RunnerName, err)}
+	}
+
+	var wg sync.WaitGroup
+	pending, latest := pendingChunks(splitRange(mi), recs)
+	if len(pending) == 0 {
+		return false, io.EOF
+	}
+
+	running := len(pending)
+	results := make(chan chunkResult, running)
+	for i, cr := range pending {
+		wg.Add(1)
+		// the chunks take the targets resolved before the run in order
+		go func(l structures.SyncRecord, cr chunkRange, target int) {
+			defer wg.Done()
+			b, err := c.runRange(ctx, rcp, l, cr, target)
+			results <- chunkResult{backoff: b, err: err}
+		}(latest[cr.Chunk], cr, i)
+	}
+	wg.Wait()
+	close(results)
+
+	rs := make([]chunkResult, 0, running)
+	for r := range results {
+		rs = append(rs, r)
+	}
+	return chunksOutcome(rs)
+}
+
+type chunkResult struct {
+	backoff bool
+	err     error
+}
+
+// chunksOutcome combines the results of chunks into the result of run.
+// Run backs off only when none of the chunks made progress, returning the first error.
+func chunksOutcome(results []chunkResult) (backoff bool, err error) {
+	var failed int
+	for _, r := range results {
+		if r.err == nil && !r.backoff {
+			continue
+		}
+		failed++
+		if err == nil {
+			err = r.err
+		}
+	}
+
+	if failed < len(results) {
+		return false, nil
+	}
+	return true, err
+}
+
+// pendingChunks returns the chunks that are not finished yet, together with the latest records of all the chunks
+func pendingChunks(chunks []chunkRange, recs []structures.SyncRecord) (pending []chunkRange, latest map[int64]structures.SyncRecord) {
+	latest = make(map[int64]structures.SyncRecord, len(recs))
+	for _, r := range recs {
+		latest[r.Chunk] = r
+	}
+
+	for _, cr := range chunks {
+		if l := latest[cr.Chunk]; l.Height != 0 && l.Height >= cr.HeightTo { // finished
+			continue
+		}
+		pending = append(pending, cr)
+	}
+	return pending, latest
+}
+
+// runRange makes a single synchronization request for the range, starting from the latest stored record.
+// Target is the index of target resolved before the run.
+func (c *Client) runRange(ctx context.Context, rcp coreStructs.RunConfigParams, latest structures.SyncRecord, cr chunkRange, target int) (backoff bool, err error) {
+	startedAt := time.Now()
 	lrec := structures.SyncRecord{
 		Hash:       latest.Hash,
 		Height:     latest.Height,
@@ -116,7 +388,10 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 		RetryCount: latest.RetryCount,
 	}
 
-	t, ok := c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID})
+	t, ok := coreStructs.TargetFromContext(ctx, target)
+	if !ok {
+		t, ok = c.dest.Get(coreStructs.NVCKey{Network: rcp.Network, Version: rcp.Version, ChainID: rcp.ChainID})
+	}
 	if !ok {
 		return false, &coreStructs.RunError{Contents: fmt.Errorf("error getting response:  %w", coreStructs.ErrNoDestinationAvailable)}
 	}
@@ -128,7 +403,7 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 
 	startHeight := latest.Height
 	if latest.Height == 0 {
-		startHeight = mi.HeightFrom
+		startHeight = cr.HeightFrom
 	}
 
 	resp, backoff, err := tr.GetLastData(ctx, t, structures.SyncDataRequest{
@@ -138,7 +413,7 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 		TaskID:  rcp.TaskID,
 
 		LastHeight:  startHeight,
-		FinalHeight: mi.HeightTo,
+		FinalHeight: cr.HeightTo,
 
 		LastHash:   latest.Hash,
 		LastTime:   latest.LastTime,
@@ -157,17 +432,21 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 			Error:      resp.Error,
 		}
 	}
+	lrec.Chunk = cr.Chunk
+	lrec.StartedAt = startedAt
 
 	// do not proceed on error
 	if len(resp.Error) != 0 {
 		lrec.Height = latest.Height
 		lrec.Error = resp.Error
+		lrec.ErrorClass = coreStructs.ErrorClassWorker
 		backoff = true
 		lrec.RetryCount++
 	}
 
 	if err != nil {
 		lrec.Error = []byte(err.Error())
+		lrec.ErrorClass = coreStructs.ClassifyError(ctx, err)
 		backoff = true
 		lrec.RetryCount++
 	}
@@ -177,12 +456,15 @@ func (c *Client) Run(ctx context.Context, rcp coreStructs.RunConfigParams) (back
 		zap.String("network", rcp.Network),
 		zap.String("chain_id", rcp.ChainID),
 		zap.String("task_id", rcp.TaskID),
+		zap.Int64("chunk", cr.Chunk),
 		zap.Uint64("req_last_height", latest.Height),
 		zap.Uint64("resp_last_height", resp.LastHeight),
 		zap.String("error", string(lrec.Error)),
 	)
 
-	if err2 := c.store.SetLatest(ctx, rcp, lrec); err2 != nil {
+	sCtx, sCancel := coreStructs.StoreContext()
+	defer sCancel()
+	if err2 := c.store.SetLatest(sCtx, rcp, lrec); err2 != nil {
 		return false, &coreStructs.RunError{Contents: fmt.Errorf("error writing last record SetLatest [%s]:  %w", RunnerName, err2)}
 	}
 
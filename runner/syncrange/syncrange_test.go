@@ -0,0 +1,136 @@
+package syncrange
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitRange(t *testing.T) {
+	tests := []struct {
+		name string
+		src  SyncRangeConfig
+		want []chunkRange
+	}{
+		{
+			name: "equal chunks",
+			src:  SyncRangeConfig{HeightFrom: 0, HeightTo: 100, Chunks: 4},
+			want: []chunkRange{{0, 0, 25}, {1, 25, 50}, {2, 50, 75}, {3, 75, 100}},
+		},
+		{
+			name: "last chunk shorter",
+			src:  SyncRangeConfig{HeightFrom: 0, HeightTo: 10, Chunks: 3},
+			want: []chunkRange{{0, 0, 4}, {1, 4, 8}, {2, 8, 10}},
+		},
+		{
+			name: "more chunks than heights",
+			src:  SyncRangeConfig{HeightFrom: 5, HeightTo: 7, Chunks: 5},
+			want: []chunkRange{{0, 5, 6}, {1, 6, 7}},
+		},
+		{
+			name: "no chunks",
+			src:  SyncRangeConfig{HeightFrom: 5, HeightTo: 7},
+			want: []chunkRange{{0, 5, 7}},
+		},
+		{
+			name: "empty range",
+			src:  SyncRangeConfig{HeightFrom: 10, HeightTo: 10, Chunks: 4},
+			want: []chunkRange{{0, 10, 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitRange(tt.src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContiguousHeight(t *testing.T) {
+	chunks := splitRange(SyncRangeConfig{HeightFrom: 0, HeightTo: 100, Chunks: 4})
+
+	tests := []struct {
+		name    string
+		heights map[int64]uint64
+		want    uint64
+	}{
+		{name: "nothing synchronized", heights: map[int64]uint64{}, want: 0},
+		{name: "first chunk in progress", heights: map[int64]uint64{0: 10, 2: 60}, want: 10},
+		{name: "second chunk in progress", heights: map[int64]uint64{0: 25, 1: 30}, want: 30},
+		{name: "second chunk not started", heights: map[int64]uint64{0: 25, 2: 75}, want: 25},
+		{name: "third chunk not started", heights: map[int64]uint64{0: 25, 1: 50, 3: 100}, want: 50},
+		{name: "all finished", heights: map[int64]uint64{0: 25, 1: 50, 2: 75, 3: 100}, want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contiguousHeight(chunks, tt.heights); got != tt.want {
+				t.Errorf("contiguousHeight() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunksOutcome(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+
+	tests := []struct {
+		name        string
+		results     []chunkResult
+		wantBackoff bool
+		wantErr     error
+	}{
+		{name: "all succeeded", results: []chunkResult{{}, {}}},
+		{name: "some failed", results: []chunkResult{{err: errA}, {}, {backoff: true}}},
+		{name: "all backed off", results: []chunkResult{{backoff: true}, {backoff: true}}, wantBackoff: true},
+		{name: "all failed", results: []chunkResult{{err: errA}, {backoff: true, err: errB}}, wantBackoff: true, wantErr: errA},
+		{name: "failed and backed off", results: []chunkResult{{backoff: true}, {err: errB}}, wantBackoff: true, wantErr: errB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backoff, err := chunksOutcome(tt.results)
+			if backoff != tt.wantBackoff || err != tt.wantErr {
+				t.Errorf("chunksOutcome() = (%t, %v), want (%t, %v)", backoff, err, tt.wantBackoff, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSyncRangeFromMapInterfaceChunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks interface{}
+		want   uint64
+		wantOk bool
+	}{
+		{name: "missing", wantOk: true},
+		{name: "string", chunks: "8", want: 8, wantOk: true},
+		{name: "json number", chunks: json.Number("8"), want: 8, wantOk: true},
+		{name: "float", chunks: float64(8), want: 8, wantOk: true},
+		{name: "fraction", chunks: 2.5},
+		{name: "negative", chunks: float64(-1)},
+		{name: "not a number", chunks: "many"},
+		{name: "wrong type", chunks: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := map[string]interface{}{"height_from": "1", "height_to": "100"}
+			if tt.chunks != nil {
+				cfg["chunks"] = tt.chunks
+			}
+
+			src, ok := SyncRangeFromMapInterface(cfg)
+			if ok != tt.wantOk {
+				t.Fatalf("ok = %t, want %t", ok, tt.wantOk)
+			}
+			if ok && src.Chunks != tt.want {
+				t.Errorf("chunks = %d, want %d", src.Chunks, tt.want)
+			}
+		})
+	}
+}
@@ -67,6 +67,15 @@ WAIT_FOR_MESSAGE:
 				break WAIT_FOR_MESSAGE
 			}
 			ld.l.Warn("Outstanding message passed", zap.Any("response", resp))
+		case <-ctx.Done():
+			return structures.SyncDataResponse{
+				LastHash:   ldReq.LastHash,
+				LastHeight: ldReq.LastHeight,
+				LastTime:   ldReq.LastTime,
+				LastEpoch:  ldReq.LastEpoch,
+				Nonce:      ldReq.Nonce,
+				RetryCount: ldReq.RetryCount + 1,
+			}, true, &coreStructs.RunError{Contents: fmt.Errorf("error getting response: %w", ctx.Err())}
 		case <-time.After(time.Minute * 5):
 			return structures.SyncDataResponse{
 				LastHash:   ldReq.LastHash,
@@ -1,6 +1,8 @@
 package structures
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -15,10 +17,15 @@ var (
 	StateFinished State = "finished"
 	StateStopped  State = "stopped"
 	StateRunning  State = "running"
+	StateArchived State = "archived"
+	StateFailed   State = "failed"
 )
 
 var (
 	ErrNoDestinationAvailable = errors.New("no destination available")
+
+	// ErrBehind is returned by runner that has made progress and should be run again, without waiting for the next interval
+	ErrBehind = errors.New("task is behind")
 )
 
 type RunConfig struct {
@@ -32,11 +39,68 @@ type RunConfig struct {
 	TaskID string `json:"task_id"`
 
 	Duration time.Duration `json:"duration"`
+	Cron     string        `json:"cron"`
 	Kind     string        `json:"kind"`
 
 	Enabled bool                   `json:"enabled"`
 	Status  State                  `json:"status"`
 	Config  map[string]interface{} `json:"config"`
+
+	// Labels are free-form key-value pairs used for selecting schedules
+	Labels map[string]string `json:"labels"`
+
+	// Timeout limits the time of every single run, zero means no limit
+	Timeout time.Duration `json:"timeout"`
+
+	// StartAt and EndAt bound the period of schedule activity, zero values mean no bound
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+
+	// StartOffset delays the first run after schedule is started.
+	// Jitter is the range of deterministic shift of runs, derived from schedule id.
+	StartOffset time.Duration `json:"start_offset"`
+	Jitter      time.Duration `json:"jitter"`
+
+	// RetryPolicy overrides the default backoff, nil keeps the default behavior
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+
+	// Dependencies have to be met before every run of schedule
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+
+	// MissedRuns decides what happens with runs missed while schedule was not running anywhere
+	MissedRuns MissedRunPolicy `json:"missed_runs"`
+
+	// LastRun, NextRun and BackoffIteration are persisted, so the schedule continues from the same point after restart
+	LastRun          time.Time `json:"last_run"`
+	NextRun          time.Time `json:"next_run"`
+	BackoffIteration uint64    `json:"backoff_iteration"`
+
+	// LastError and FailedAt describe the reason of schedule being in failed state
+	LastError string    `json:"last_error,omitempty"`
+	FailedAt  time.Time `json:"failed_at"`
+
+	Revision     uint64    `json:"revision"`
+	LeaseExpires time.Time `json:"lease_expires"`
+}
+
+// ValidateWindow checks if the activity period of schedule is correct
+func (rc RunConfig) ValidateWindow() error {
+	if !rc.StartAt.IsZero() && !rc.EndAt.IsZero() && !rc.EndAt.After(rc.StartAt) {
+		return errors.New("end_at has to be after start_at")
+	}
+	return nil
+}
+
+// Params returns parameters passed to the runner
+func (rc RunConfig) Params() RunConfigParams {
+	return RunConfigParams{
+		Network: rc.Network,
+		ChainID: rc.ChainID,
+		TaskID:  rc.TaskID,
+		Version: rc.Version,
+		Config:  rc.Config,
+		Kind:    rc.Kind,
+	}
 }
 
 type RunConfigParams struct {
@@ -51,6 +115,157 @@ type RunConfigParams struct {
 	Version string `json:"version"`
 }
 
+// MissedRunPolicy decides how to handle activations, that were missed because schedule was not running
+type MissedRunPolicy string
+
+var (
+	// MissedRunSkip waits for the next regular activation, it's the default
+	MissedRunSkip MissedRunPolicy = "skip"
+	// MissedRunOnce runs once right away, no matter how many activations were missed
+	MissedRunOnce MissedRunPolicy = "once"
+	// MissedRunAll runs every missed activation, one after another
+	MissedRunAll MissedRunPolicy = "all"
+)
+
+func (mp MissedRunPolicy) Validate() error {
+	switch mp {
+	case "", MissedRunSkip, MissedRunOnce, MissedRunAll:
+		return nil
+	}
+	return fmt.Errorf("unknown missed runs policy: %s", mp)
+}
+
+// RunState is the progress of schedule, persisted after every activation
+type RunState struct {
+	LastRun          time.Time
+	NextRun          time.Time
+	BackoffIteration uint64
+}
+
+type JitterMode string
+
+var (
+	JitterNone  JitterMode = "none"
+	JitterFull  JitterMode = "full"
+	JitterEqual JitterMode = "equal"
+)
+
+// RetryPolicy describes delays between consecutive failed or backed off runs.
+// The n-th delay equals BaseDelay * Multiplier^(n-1), capped by MaxDelay.
+// After MaxFailures consecutive errors schedule is stopped and marked as failed, zero means it never gives up.
+type RetryPolicy struct {
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Multiplier  float64
+	Jitter      JitterMode
+	MaxFailures uint64
+}
+
+type retryPolicyJSON struct {
+	BaseDelay   string     `json:"base_delay,omitempty"`
+	MaxDelay    string     `json:"max_delay,omitempty"`
+	Multiplier  float64    `json:"multiplier,omitempty"`
+	Jitter      JitterMode `json:"jitter,omitempty"`
+	MaxFailures uint64     `json:"max_failures,omitempty"`
+}
+
+// MarshalJSON writes durations in human readable form like `10s`
+func (rp RetryPolicy) MarshalJSON() ([]byte, error) {
+	rpj := retryPolicyJSON{Multiplier: rp.Multiplier, Jitter: rp.Jitter, MaxFailures: rp.MaxFailures}
+	if rp.BaseDelay > 0 {
+		rpj.BaseDelay = rp.BaseDelay.String()
+	}
+	if rp.MaxDelay > 0 {
+		rpj.MaxDelay = rp.MaxDelay.String()
+	}
+	return json.Marshal(rpj)
+}
+
+func (rp *RetryPolicy) UnmarshalJSON(b []byte) (err error) {
+	rpj := retryPolicyJSON{}
+	if err := json.Unmarshal(b, &rpj); err != nil {
+		return err
+	}
+
+	*rp = RetryPolicy{Multiplier: rpj.Multiplier, Jitter: rpj.Jitter, MaxFailures: rpj.MaxFailures}
+	if rpj.BaseDelay != "" {
+		if rp.BaseDelay, err = time.ParseDuration(rpj.BaseDelay); err != nil {
+			return fmt.Errorf("error parsing base_delay: %w", err)
+		}
+	}
+	if rpj.MaxDelay != "" {
+		if rp.MaxDelay, err = time.ParseDuration(rpj.MaxDelay); err != nil {
+			return fmt.Errorf("error parsing max_delay: %w", err)
+		}
+	}
+	return nil
+}
+
+func (rp RetryPolicy) Validate() error {
+	if rp.BaseDelay < 0 || rp.MaxDelay < 0 {
+		return errors.New("retry policy delays cannot be negative")
+	}
+	if rp.MaxDelay > 0 && rp.BaseDelay > rp.MaxDelay {
+		return errors.New("retry policy base_delay cannot be greater than max_delay")
+	}
+	if rp.Multiplier != 0 && rp.Multiplier < 1 {
+		return errors.New("retry policy multiplier has to be at least 1")
+	}
+	switch rp.Jitter {
+	case "", JitterNone, JitterFull, JitterEqual:
+	default:
+		return fmt.Errorf("unknown retry policy jitter mode: %s", rp.Jitter)
+	}
+	return nil
+}
+
+// Dependency is a condition on the latest run of other schedule.
+// Network, ChainID and Version default to the ones of dependent schedule.
+type Dependency struct {
+	Kind    string `json:"kind"`
+	TaskID  string `json:"task_id"`
+	Network string `json:"network,omitempty"`
+	ChainID string `json:"chain_id,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// MinHeight requires the latest run of dependency to reach given height
+	MinHeight uint64 `json:"min_height,omitempty"`
+	// Succeeded requires the latest run of dependency to finish without error
+	Succeeded bool `json:"succeeded,omitempty"`
+}
+
+func (d Dependency) Validate() error {
+	if d.Kind == "" || d.TaskID == "" {
+		return errors.New("dependency requires kind and task_id")
+	}
+	if d.MinHeight == 0 && !d.Succeeded {
+		return fmt.Errorf("dependency on %s/%s requires min_height or succeeded condition", d.Kind, d.TaskID)
+	}
+	return nil
+}
+
+// Params returns parameters identifying the dependency runs, for the dependent schedule
+func (d Dependency) Params(rcp RunConfigParams) RunConfigParams {
+	dp := RunConfigParams{Kind: d.Kind, TaskID: d.TaskID, Network: d.Network, ChainID: d.ChainID, Version: d.Version}
+	if dp.Network == "" {
+		dp.Network = rcp.Network
+	}
+	if dp.ChainID == "" {
+		dp.ChainID = rcp.ChainID
+	}
+	if dp.Version == "" {
+		dp.Version = rcp.Version
+	}
+	return dp
+}
+
+// LatestStatus describes the latest run of a task, as stored by runner
+type LatestStatus struct {
+	Height    uint64    `json:"height"`
+	Time      time.Time `json:"time"`
+	Succeeded bool      `json:"succeeded"`
+}
+
 type RunError struct {
 	Contents      error
 	Unrecoverable bool
@@ -64,6 +279,49 @@ func (re *RunError) IsRecoverable() bool {
 	return !re.Unrecoverable
 }
 
+func (re *RunError) Unwrap() error {
+	return re.Contents
+}
+
+// Error classes stored in runners history
+const (
+	ErrorClassTimeout = "timeout"
+	ErrorClassWorker  = "worker"
+)
+
+// StoreTimeout limits writing the result of run
+const StoreTimeout = 10 * time.Second
+
+// StoreContext returns the context of writing the result of run. It's detached from the context of run,
+// so runs that exceeded their deadline are recorded as well.
+func StoreContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), StoreTimeout)
+}
+
+type targetsKey struct{}
+
+// WithTargets returns the context carrying the targets chosen for the run before it started
+func WithTargets(ctx context.Context, targets []Target) context.Context {
+	return context.WithValue(ctx, targetsKey{}, targets)
+}
+
+// TargetFromContext returns i-th target chosen for the run, runners choose the target on their own if there is none
+func TargetFromContext(ctx context.Context, i int) (t Target, ok bool) {
+	targets, _ := ctx.Value(targetsKey{}).([]Target)
+	if i >= len(targets) {
+		return t, false
+	}
+	return targets[i], true
+}
+
+// ClassifyError distinguishes runs that exceeded their deadline from errors returned by workers
+func ClassifyError(ctx context.Context, err error) string {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	return ErrorClassWorker
+}
+
 type Target struct {
 	ChainID          string                 `json:"chain_id"`
 	Network          string                 `json:"network"`